@@ -0,0 +1,152 @@
+// Package apperrors defines the typed errors returned by repositories and services.
+// Every error has a Kind, which decides the HTTP status, and a machine readable Code
+// that clients can branch on without parsing the message.
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+type Kind string
+
+const (
+	KindBadRequest        Kind = "bad_request"
+	KindNotFound          Kind = "not_found"
+	KindValidation        Kind = "validation"
+	KindConflict          Kind = "conflict"
+	KindInsufficientStock Kind = "insufficient_stock"
+	KindUnauthorized      Kind = "unauthorized"
+)
+
+// Code returned for errors that are not an *Error
+const CodeInternal = "internal_error"
+
+// Error.Fields lists the failed fields of a validation error
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+// FieldError is one failed rule, Field is the JSON path such as "items[0].quantity"
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// FieldErrors collects the failures of a validation pass
+type FieldErrors []FieldError
+
+func (f *FieldErrors) Add(field string, code string, format string, args ...any) {
+	message := format
+	if len(args) > 0 {
+		message = fmt.Sprintf(format, args...)
+	}
+	*f = append(*f, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Err returns nil when nothing failed, otherwise a validation error carrying the fields
+func (f FieldErrors) Err() error {
+	if len(f) == 0 {
+		return nil
+	}
+
+	return &Error{Kind: KindValidation, Code: "validation_failed", Message: "Validation failed", Fields: f}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches a target of the same kind, and of the same code when the target has one,
+// so errors.Is(err, ErrNotFound) matches every not found error
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+
+	return e.Kind == t.Kind && (t.Code == "" || e.Code == t.Code)
+}
+
+// Sentinels to test an error's kind with errors.Is
+var (
+	ErrBadRequest        = &Error{Kind: KindBadRequest, Message: "bad request"}
+	ErrNotFound          = &Error{Kind: KindNotFound, Message: "not found"}
+	ErrValidation        = &Error{Kind: KindValidation, Message: "validation failed"}
+	ErrConflict          = &Error{Kind: KindConflict, Message: "conflict"}
+	ErrInsufficientStock = &Error{Kind: KindInsufficientStock, Message: "insufficient stock"}
+	ErrUnauthorized      = &Error{Kind: KindUnauthorized, Message: "unauthorized"}
+)
+
+func newError(kind Kind, code string, format string, args []any) *Error {
+	message := format
+	if len(args) > 0 {
+		message = fmt.Sprintf(format, args...)
+	}
+
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// BadRequest is for requests that can't be read at all, such as malformed JSON or
+// query params. Validation is for well formed requests that break a rule.
+func BadRequest(code string, format string, args ...any) *Error {
+	return newError(KindBadRequest, code, format, args)
+}
+
+func NotFound(code string, format string, args ...any) *Error {
+	return newError(KindNotFound, code, format, args)
+}
+
+func Validation(code string, format string, args ...any) *Error {
+	return newError(KindValidation, code, format, args)
+}
+
+func Conflict(code string, format string, args ...any) *Error {
+	return newError(KindConflict, code, format, args)
+}
+
+func InsufficientStock(code string, format string, args ...any) *Error {
+	return newError(KindInsufficientStock, code, format, args)
+}
+
+func Unauthorized(code string, format string, args ...any) *Error {
+	return newError(KindUnauthorized, code, format, args)
+}
+
+// As returns the *Error in err's chain, or nil when there is none
+func As(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	return nil
+}
+
+// Status is the HTTP status for err. Anything that is not an *Error is a 500.
+func Status(err error) int {
+	appErr := As(err)
+	if appErr == nil {
+		return http.StatusInternalServerError
+	}
+
+	switch appErr.Kind {
+	case KindBadRequest:
+		return http.StatusBadRequest
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindValidation:
+		return http.StatusUnprocessableEntity
+	case KindConflict, KindInsufficientStock:
+		return http.StatusConflict
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"store-api-go/internal/httpx"
+	"strings"
+)
+
+type contextKey struct{}
+
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// ClaimsFromContext returns the authenticated caller, nil for public routes
+func ClaimsFromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(contextKey{}).(*Claims)
+	return claims
+}
+
+// MethodRoles maps an HTTP method to the minimum role allowed to call it.
+// Methods that are not listed only need a valid token.
+type MethodRoles map[string]string
+
+// Require checks the bearer token and the caller's role before calling next
+func (m *TokenManager) Require(roles MethodRoles, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tokenString, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || tokenString == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			httpx.WriteFail(w, http.StatusUnauthorized, "missing_token", "Missing bearer token")
+			return
+		}
+
+		claims, err := m.Verify(tokenString)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			httpx.WriteFail(w, http.StatusUnauthorized, "invalid_token", "Invalid or expired token")
+			return
+		}
+
+		if required, listed := roles[r.Method]; listed && !HasRole(claims.Role, required) {
+			httpx.WriteFail(w, http.StatusForbidden, "forbidden", "Requires "+required+" role")
+			return
+		}
+
+		next(w, r.WithContext(WithClaims(r.Context(), claims)))
+	}
+}
+
+// Middleware is Require for a group of routes
+func (m *TokenManager) Middleware(roles MethodRoles) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return m.Require(roles, next.ServeHTTP)
+	}
+}
@@ -0,0 +1,20 @@
+package auth
+
+import "store-api-go/internal/models"
+
+// role rank, a higher rank includes every permission of the lower ones
+var roleRank = map[string]int{
+	models.RoleCashier: 1,
+	models.RoleManager: 2,
+	models.RoleAdmin:   3,
+}
+
+func IsValidRole(role string) bool {
+	_, exists := roleRank[role]
+	return exists
+}
+
+// HasRole reports whether role is at least as privileged as required
+func HasRole(role string, required string) bool {
+	return IsValidRole(role) && roleRank[role] >= roleRank[required]
+}
@@ -0,0 +1,354 @@
+// Package config loads the API's settings. Every setting is named by its environment
+// variable and read from, later sources winning:
+//
+//  1. the defaults below
+//  2. the file named by CONFIG_FILE, a .yaml, .yml, .toml, .json or .env file using the
+//     variable names as keys, or ./.env when CONFIG_FILE is unset and it exists
+//  3. environment variables
+//
+// Blank values in the file count as unset, so they don't override the defaults.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// storage backends
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+// Values of the enum settings. They mirror the constants of the packages that use
+// them, config_test.go keeps the two in step, so config depends on none of them.
+var (
+	deletePolicies   = []string{"restrict", "cascade", "detach"}
+	taxModes         = []string{"inclusive", "exclusive"}
+	logFormats       = []string{"json", "text"}
+	tracingExporters = []string{"none", "otlp", "stdout"}
+)
+
+// maxTaxRate is 100% in basis points
+const maxTaxRate = 10000
+
+// FileEnv names the config file, it is only read from the environment
+const FileEnv = "CONFIG_FILE"
+
+// placeholders once shipped in .env.example, refused so nobody runs with a known secret
+var placeholders = []string{
+	"change-me-to-a-long-random-secret-value",
+	"change-me-please",
+}
+
+type Config struct {
+	Port           string `mapstructure:"PORT"`
+	BaseURL        string `mapstructure:"BASE_URL"`
+	StorageDriver  string `mapstructure:"STORAGE_DRIVER"`
+	DBConn         string `mapstructure:"DB_CONN"`
+	DBMaxOpenConns int    `mapstructure:"DB_MAX_OPEN_CONNECTION"`
+	DBMaxIdleConns int    `mapstructure:"DB_MAX_IDLE_CONNECTION"`
+	DBAutoMigrate  bool   `mapstructure:"DB_AUTO_MIGRATE"`
+
+	CategoryDeletePolicy string `mapstructure:"CATEGORY_DELETE_POLICY"`
+	DefaultLocationID    int    `mapstructure:"DEFAULT_LOCATION_ID"`
+	TaxPricingMode       string `mapstructure:"TAX_PRICING_MODE"`
+	TaxDefaultRate       int    `mapstructure:"TAX_DEFAULT_RATE"`
+	TimeZone             string `mapstructure:"TIME_ZONE"`
+
+	ReceiptStoreName    string `mapstructure:"RECEIPT_STORE_NAME"`
+	ReceiptStoreAddress string `mapstructure:"RECEIPT_STORE_ADDRESS"`
+	ReceiptFooter       string `mapstructure:"RECEIPT_FOOTER"`
+	ReceiptTemplateDir  string `mapstructure:"RECEIPT_TEMPLATE_DIR"`
+
+	AuthSecret        string        `mapstructure:"AUTH_SECRET"`
+	AuthTokenTTL      time.Duration `mapstructure:"AUTH_TOKEN_TTL"`
+	AuthAdminUsername string        `mapstructure:"AUTH_ADMIN_USERNAME"`
+	AuthAdminPassword string        `mapstructure:"AUTH_ADMIN_PASSWORD"`
+
+	IdempotencyTTL time.Duration `mapstructure:"IDEMPOTENCY_TTL"`
+
+	HTTPReadTimeout       time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	HTTPReadHeaderTimeout time.Duration `mapstructure:"HTTP_READ_HEADER_TIMEOUT"`
+	HTTPWriteTimeout      time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	HTTPIdleTimeout       time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout       time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	ShutdownDelay         time.Duration `mapstructure:"SHUTDOWN_DELAY"`
+	CORSAllowedOrigins    []string      `mapstructure:"CORS_ALLOWED_ORIGINS"`
+
+	HealthCheckTimeout time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT"`
+	MetricsAddr        string        `mapstructure:"METRICS_ADDR"`
+
+	LogLevel  string `mapstructure:"LOG_LEVEL"`
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+
+	TracingExporter     string  `mapstructure:"TRACING_EXPORTER"`
+	TracingServiceName  string  `mapstructure:"TRACING_SERVICE_NAME"`
+	TracingSampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	TracingOTLPEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingFile         string  `mapstructure:"TRACING_FILE"`
+}
+
+var defaults = map[string]any{
+	"PORT":                   "8080",
+	"STORAGE_DRIVER":         StoragePostgres,
+	"DB_MAX_OPEN_CONNECTION": 25,
+	"DB_MAX_IDLE_CONNECTION": 5,
+	"DB_AUTO_MIGRATE":        false,
+
+	"CATEGORY_DELETE_POLICY": "restrict",
+	// the first location, created by the locations migration
+	"DEFAULT_LOCATION_ID": 1,
+	// shelf prices are what customers pay unless configured otherwise
+	"TAX_PRICING_MODE": "inclusive",
+	"TAX_DEFAULT_RATE": 0,
+	"TIME_ZONE":        "UTC",
+
+	"AUTH_TOKEN_TTL":  12 * time.Hour,
+	"IDEMPOTENCY_TTL": 24 * time.Hour,
+
+	"HTTP_READ_TIMEOUT":        15 * time.Second,
+	"HTTP_READ_HEADER_TIMEOUT": 5 * time.Second,
+	"HTTP_WRITE_TIMEOUT":       30 * time.Second,
+	"HTTP_IDLE_TIMEOUT":        60 * time.Second,
+	"SHUTDOWN_TIMEOUT":         30 * time.Second,
+	"SHUTDOWN_DELAY":           time.Duration(0),
+	"HEALTH_CHECK_TIMEOUT":     2 * time.Second,
+
+	"LOG_LEVEL":  "info",
+	"LOG_FORMAT": "json",
+
+	"TRACING_EXPORTER":     "none",
+	"TRACING_SERVICE_NAME": "store-api-go",
+	"TRACING_SAMPLE_RATIO": 1.0,
+}
+
+// Load reads the settings without checking them, see Validate
+func Load() (Config, error) {
+	v := viper.New()
+	v.AutomaticEnv()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+
+	file := os.Getenv(FileEnv)
+	if file == "" {
+		if _, err := os.Stat(".env"); err == nil {
+			file = ".env"
+		}
+	}
+	if file != "" {
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return Config{}, fmt.Errorf("read config file %s: %w", file, err)
+		}
+	}
+
+	for key, value := range defaults {
+		if v.GetString(key) == "" {
+			v.Set(key, value)
+		}
+	}
+
+	return Config{
+		Port:           v.GetString("PORT"),
+		BaseURL:        v.GetString("BASE_URL"),
+		StorageDriver:  v.GetString("STORAGE_DRIVER"),
+		DBConn:         v.GetString("DB_CONN"),
+		DBMaxOpenConns: v.GetInt("DB_MAX_OPEN_CONNECTION"),
+		DBMaxIdleConns: v.GetInt("DB_MAX_IDLE_CONNECTION"),
+		DBAutoMigrate:  v.GetBool("DB_AUTO_MIGRATE"),
+
+		CategoryDeletePolicy: v.GetString("CATEGORY_DELETE_POLICY"),
+		DefaultLocationID:    v.GetInt("DEFAULT_LOCATION_ID"),
+		TaxPricingMode:       v.GetString("TAX_PRICING_MODE"),
+		TaxDefaultRate:       v.GetInt("TAX_DEFAULT_RATE"),
+		TimeZone:             v.GetString("TIME_ZONE"),
+
+		ReceiptStoreName:    v.GetString("RECEIPT_STORE_NAME"),
+		ReceiptStoreAddress: v.GetString("RECEIPT_STORE_ADDRESS"),
+		ReceiptFooter:       v.GetString("RECEIPT_FOOTER"),
+		ReceiptTemplateDir:  v.GetString("RECEIPT_TEMPLATE_DIR"),
+
+		AuthSecret:        v.GetString("AUTH_SECRET"),
+		AuthTokenTTL:      v.GetDuration("AUTH_TOKEN_TTL"),
+		AuthAdminUsername: v.GetString("AUTH_ADMIN_USERNAME"),
+		AuthAdminPassword: v.GetString("AUTH_ADMIN_PASSWORD"),
+
+		IdempotencyTTL: v.GetDuration("IDEMPOTENCY_TTL"),
+
+		HTTPReadTimeout:       v.GetDuration("HTTP_READ_TIMEOUT"),
+		HTTPReadHeaderTimeout: v.GetDuration("HTTP_READ_HEADER_TIMEOUT"),
+		HTTPWriteTimeout:      v.GetDuration("HTTP_WRITE_TIMEOUT"),
+		HTTPIdleTimeout:       v.GetDuration("HTTP_IDLE_TIMEOUT"),
+		ShutdownTimeout:       v.GetDuration("SHUTDOWN_TIMEOUT"),
+		ShutdownDelay:         v.GetDuration("SHUTDOWN_DELAY"),
+		CORSAllowedOrigins:    splitList(v.GetString("CORS_ALLOWED_ORIGINS")),
+
+		HealthCheckTimeout: v.GetDuration("HEALTH_CHECK_TIMEOUT"),
+		MetricsAddr:        v.GetString("METRICS_ADDR"),
+
+		LogLevel:  v.GetString("LOG_LEVEL"),
+		LogFormat: v.GetString("LOG_FORMAT"),
+
+		TracingExporter:     v.GetString("TRACING_EXPORTER"),
+		TracingServiceName:  v.GetString("TRACING_SERVICE_NAME"),
+		TracingSampleRatio:  v.GetFloat64("TRACING_SAMPLE_RATIO"),
+		TracingOTLPEndpoint: v.GetString("TRACING_OTLP_ENDPOINT"),
+		TracingFile:         v.GetString("TRACING_FILE"),
+	}, nil
+}
+
+// Validate reports every invalid setting at once, each naming its variable
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(key string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		fail("PORT", "must be a port number between 1 and 65535, got %q", c.Port)
+	}
+	if strings.Contains(c.BaseURL, "://") {
+		fail("BASE_URL", "is the host to listen on, got the URL %q", c.BaseURL)
+	}
+	if c.MetricsAddr != "" {
+		if _, port, err := net.SplitHostPort(c.MetricsAddr); err != nil || port == "" {
+			fail("METRICS_ADDR", "must be a host:port to serve metrics on, got %q", c.MetricsAddr)
+		} else if port == c.Port {
+			fail("METRICS_ADDR", "must not share the API's port %s", c.Port)
+		}
+	}
+
+	switch c.StorageDriver {
+	case StoragePostgres:
+		if c.DBConn == "" {
+			fail("DB_CONN", "is required with STORAGE_DRIVER=%s", StoragePostgres)
+		}
+	case StorageMemory:
+	default:
+		fail("STORAGE_DRIVER", "must be %q or %q, got %q", StoragePostgres, StorageMemory, c.StorageDriver)
+	}
+	if c.DBMaxOpenConns < 1 {
+		fail("DB_MAX_OPEN_CONNECTION", "must be at least 1, got %d", c.DBMaxOpenConns)
+	}
+	if c.DBMaxIdleConns < 0 || c.DBMaxIdleConns > c.DBMaxOpenConns {
+		fail("DB_MAX_IDLE_CONNECTION", "must be between 0 and DB_MAX_OPEN_CONNECTION, got %d", c.DBMaxIdleConns)
+	}
+
+	if !slices.Contains(deletePolicies, c.CategoryDeletePolicy) {
+		fail("CATEGORY_DELETE_POLICY", "must be %s, got %q", alternatives(deletePolicies), c.CategoryDeletePolicy)
+	}
+	if c.DefaultLocationID < 1 {
+		fail("DEFAULT_LOCATION_ID", "must be a location id, got %d", c.DefaultLocationID)
+	}
+	if !slices.Contains(taxModes, c.TaxPricingMode) {
+		fail("TAX_PRICING_MODE", "must be %s, got %q", alternatives(taxModes), c.TaxPricingMode)
+	}
+	if c.TaxDefaultRate < 0 || c.TaxDefaultRate > maxTaxRate {
+		fail("TAX_DEFAULT_RATE", "must be basis points between 0 and %d, got %d", maxTaxRate, c.TaxDefaultRate)
+	}
+	if _, err := time.LoadLocation(c.TimeZone); err != nil {
+		fail("TIME_ZONE", "must be an IANA time zone name such as Asia/Jakarta, got %q", c.TimeZone)
+	}
+
+	if len(c.AuthSecret) < 32 {
+		fail("AUTH_SECRET", "must be set to at least 32 characters")
+	} else if slices.Contains(placeholders, c.AuthSecret) {
+		fail("AUTH_SECRET", "is the example placeholder, set a random secret")
+	}
+	if c.AuthAdminUsername != "" && c.AuthAdminPassword == "" {
+		fail("AUTH_ADMIN_PASSWORD", "is required with AUTH_ADMIN_USERNAME")
+	} else if slices.Contains(placeholders, c.AuthAdminPassword) {
+		fail("AUTH_ADMIN_PASSWORD", "is the example placeholder, choose a password")
+	}
+
+	positive := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"AUTH_TOKEN_TTL", c.AuthTokenTTL},
+		{"IDEMPOTENCY_TTL", c.IdempotencyTTL},
+		{"HTTP_READ_TIMEOUT", c.HTTPReadTimeout},
+		{"HTTP_READ_HEADER_TIMEOUT", c.HTTPReadHeaderTimeout},
+		{"HTTP_WRITE_TIMEOUT", c.HTTPWriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", c.HTTPIdleTimeout},
+		{"SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+		{"HEALTH_CHECK_TIMEOUT", c.HealthCheckTimeout},
+	}
+	for _, setting := range positive {
+		if setting.value <= 0 {
+			fail(setting.key, "must be a positive duration, got %s", setting.value)
+		}
+	}
+	if c.ShutdownDelay < 0 {
+		fail("SHUTDOWN_DELAY", "must not be negative, got %s", c.ShutdownDelay)
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		fail("LOG_LEVEL", "must be debug, info, warn or error, got %q", c.LogLevel)
+	}
+	if !slices.Contains(logFormats, strings.ToLower(c.LogFormat)) {
+		fail("LOG_FORMAT", "must be %s, got %q", alternatives(logFormats), c.LogFormat)
+	}
+
+	if !slices.Contains(tracingExporters, c.TracingExporter) {
+		fail("TRACING_EXPORTER", "must be %s, got %q", alternatives(tracingExporters), c.TracingExporter)
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		fail("TRACING_SAMPLE_RATIO", "must be a fraction between 0 and 1, got %g", c.TracingSampleRatio)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Addr is the address the server listens on, all interfaces when BASE_URL is blank
+func (c Config) Addr() string {
+	return net.JoinHostPort(c.BaseURL, c.Port)
+}
+
+// Location is the TIME_ZONE, UTC when it doesn't load. Validate reports that.
+func (c Config) Location() *time.Location {
+	location, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// alternatives lists values as "a", "b" or "c"
+func alternatives(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	if len(quoted) < 2 {
+		return strings.Join(quoted, "")
+	}
+
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+// splitList reads a comma separated setting, dropping blanks
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
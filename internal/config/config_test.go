@@ -0,0 +1,86 @@
+package config
+
+import (
+	"slices"
+	"store-api-go/internal/logging"
+	"store-api-go/internal/models"
+	"store-api-go/internal/services"
+	"store-api-go/internal/tracing"
+	"strings"
+	"testing"
+)
+
+// TestEnumsMatchPackages keeps the values config accepts in step with the packages
+// using them, which config doesn't import
+func TestEnumsMatchPackages(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"delete policies", deletePolicies, []string{services.DeletePolicyRestrict, services.DeletePolicyCascade, services.DeletePolicyDetach}},
+		{"tax modes", taxModes, []string{models.TaxInclusive, models.TaxExclusive}},
+		{"log formats", logFormats, []string{logging.FormatJSON, logging.FormatText}},
+		{"tracing exporters", tracingExporters, []string{tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout}},
+	}
+
+	for _, test := range tests {
+		if !slices.Equal(test.got, test.want) {
+			t.Errorf("%s are %v, want %v", test.name, test.got, test.want)
+		}
+	}
+	for _, policy := range deletePolicies {
+		if !services.IsValidDeletePolicy(policy) {
+			t.Errorf("services refuses delete policy %q", policy)
+		}
+	}
+	for _, mode := range taxModes {
+		if !services.IsValidTaxMode(mode) {
+			t.Errorf("services refuses tax mode %q", mode)
+		}
+	}
+	if maxTaxRate != services.MaxTaxRate {
+		t.Errorf("max tax rate is %d, want %d", maxTaxRate, services.MaxTaxRate)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() Config {
+		return Config{
+			Port: "8080", StorageDriver: StorageMemory, DBMaxOpenConns: 1,
+			CategoryDeletePolicy: "restrict", DefaultLocationID: 1, TaxPricingMode: "inclusive", TimeZone: "UTC",
+			AuthSecret: strings.Repeat("s", 32), AuthTokenTTL: 1, IdempotencyTTL: 1,
+			HTTPReadTimeout: 1, HTTPReadHeaderTimeout: 1, HTTPWriteTimeout: 1, HTTPIdleTimeout: 1,
+			ShutdownTimeout: 1, HealthCheckTimeout: 1,
+			LogLevel: "info", LogFormat: "JSON", TracingExporter: "none",
+		}
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("valid config failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   string
+	}{
+		{"delete policy", func(c *Config) { c.CategoryDeletePolicy = "purge" },
+			`CATEGORY_DELETE_POLICY must be "restrict", "cascade" or "detach", got "purge"`},
+		{"tax mode", func(c *Config) { c.TaxPricingMode = "net" }, `TAX_PRICING_MODE must be "inclusive" or "exclusive"`},
+		{"tax rate", func(c *Config) { c.TaxDefaultRate = maxTaxRate + 1 }, "TAX_DEFAULT_RATE"},
+		{"log format", func(c *Config) { c.LogFormat = "xml" }, `LOG_FORMAT must be "json" or "text"`},
+		{"tracing exporter", func(c *Config) { c.TracingExporter = "jaeger" }, "TRACING_EXPORTER"},
+		{"metrics addr", func(c *Config) { c.MetricsAddr = "9091" }, "METRICS_ADDR must be a host:port"},
+		{"metrics port", func(c *Config) { c.MetricsAddr = "localhost:8080" }, "METRICS_ADDR must not share"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := valid()
+			test.change(&config)
+			if err := config.Validate(); err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got %v, want %s", err, test.want)
+			}
+		})
+	}
+}
@@ -2,14 +2,29 @@ package database
 
 import (
 	"database/sql"
-	"log"
+	"log/slog"
 
-	_ "github.com/jackc/pgx/v5/stdlib" // registers "pgx" driver for database/sql
+	"github.com/XSAM/otelsql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
-func InitDB(connectionString string) (*sql.DB, error) {
-	// Open database
-	db, err := sql.Open("pgx", connectionString)
+// InitDB connects and keeps at most maxOpenConns connections open, maxIdleConns of
+// them idle. Sessions use timeZone, so dates cast from timestamps fall on the same
+// day as in the app.
+func InitDB(connectionString string, maxOpenConns int, maxIdleConns int, timeZone string) (*sql.DB, error) {
+	config, err := pgx.ParseConfig(connectionString)
+	if err != nil {
+		return nil, err
+	}
+	config.RuntimeParams["timezone"] = timeZone
+
+	// Open database, every statement gets a span
+	db, err := otelsql.Open("pgx", stdlib.RegisterConnConfig(config),
+		otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true}),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -20,10 +35,10 @@ func InitDB(connectionString string) (*sql.DB, error) {
 		return nil, err
 	}
 
-	// Set connection pool settings (optional but recommended)
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
+	// Set connection pool settings
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxIdleConns)
 
-	log.Println("Database connected successfully")
+	slog.Info("Database connected")
 	return db, nil
 }
@@ -0,0 +1,308 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// arbitrary key for pg_advisory_xact_lock so only one instance migrates at a time
+const migrationLockKey = 7_301_245
+
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// LoadMigrations reads the embedded migrations/{version}_{name}.{up|down}.sql files
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+
+		base, direction, ok := strings.Cut(strings.TrimSuffix(fileName, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("migration %s: expected {version}_{name}.up.sql or .down.sql", fileName)
+		}
+
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: missing name", fileName)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version", fileName)
+		}
+
+		content, err := migrationFiles.ReadFile(path.Join("migrations", fileName))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration %d has mismatched names %q and %q", version, migration.Name, name)
+		}
+
+		if direction == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+func (m *Migrator) ensureVersionTable(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		checksum   TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+
+	return err
+}
+
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+func (m *Migrator) applied(q interface {
+	Query(string, ...any) (*sql.Rows, error)
+}) (map[int]appliedMigration, error) {
+	rows, err := q.Query("SELECT version, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var version int
+		var migration appliedMigration
+		if err := rows.Scan(&version, &migration.checksum, &migration.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = migration
+	}
+
+	return applied, rows.Err()
+}
+
+// verify makes sure every applied migration still exists with the same contents
+func (m *Migrator) verify(applied map[int]appliedMigration) error {
+	known := make(map[int]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	for version, appliedMigration := range applied {
+		migration, exists := known[version]
+		if !exists {
+			return fmt.Errorf("migration %d is applied but missing from this build", version)
+		}
+		if migration.Checksum != appliedMigration.checksum {
+			return fmt.Errorf("migration %d_%s was modified after it was applied", version, migration.Name)
+		}
+	}
+
+	return nil
+}
+
+// begin opens a transaction holding the migration lock with the version table in place
+func (m *Migrator) begin() (*sql.Tx, error) {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("SELECT pg_advisory_xact_lock($1)", migrationLockKey)
+	if err == nil {
+		err = m.ensureVersionTable(tx)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// Up applies every pending migration in a single transaction and returns how many ran
+func (m *Migrator) Up() (int, error) {
+	tx, err := m.begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	applied, err := m.applied(tx)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.verify(applied); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, done := applied[migration.Version]; done {
+			continue
+		}
+
+		if _, err := tx.Exec(migration.Up); err != nil {
+			return 0, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		_, err = tx.Exec("INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+			migration.Version, migration.Name, migration.Checksum)
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	return count, tx.Commit()
+}
+
+// Down reverts the latest applied migrations, newest first
+func (m *Migrator) Down(steps int) (int, error) {
+	tx, err := m.begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	applied, err := m.applied(tx)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.verify(applied); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := m.migrations[i]
+		if _, done := applied[migration.Version]; !done {
+			continue
+		}
+		if migration.Down == "" {
+			return 0, fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+
+		if _, err := tx.Exec(migration.Down); err != nil {
+			return 0, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version = $1", migration.Version); err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	return count, tx.Commit()
+}
+
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	tx, err := m.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	applied, err := m.applied(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if appliedMigration, done := applied[migration.Version]; done {
+			appliedAt := appliedMigration.appliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, tx.Commit()
+}
+
+// Version returns the highest applied migration version, 0 when nothing is applied
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	var version int
+	err := m.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+
+	return version, err
+}
+
+// Latest returns the highest migration version in this build
+func (m *Migrator) Latest() int {
+	latest := 0
+	for _, migration := range m.migrations {
+		latest = max(latest, migration.Version)
+	}
+
+	return latest
+}
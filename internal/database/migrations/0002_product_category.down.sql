@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_category_id;
+
+ALTER TABLE products DROP COLUMN IF EXISTS category_id;
@@ -0,0 +1,3 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category_id INTEGER REFERENCES categories (id);
+
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products (category_id);
@@ -0,0 +1,13 @@
+ALTER TABLE transactions
+    ADD COLUMN paid_amount   INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN change_amount INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE transaction_payments (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    method         VARCHAR(20) NOT NULL,
+    amount         INTEGER NOT NULL CHECK (amount > 0),
+    reference      VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_transaction_payments_transaction_id ON transaction_payments (transaction_id);
@@ -0,0 +1,21 @@
+CREATE TABLE refunds (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    amount         INTEGER NOT NULL,
+    reason         TEXT NOT NULL DEFAULT '',
+    restock        BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_refunds_transaction_id ON refunds (transaction_id);
+CREATE INDEX idx_refunds_created_at ON refunds (created_at);
+
+CREATE TABLE refund_items (
+    id                    SERIAL PRIMARY KEY,
+    refund_id             INTEGER NOT NULL REFERENCES refunds (id) ON DELETE CASCADE,
+    transaction_detail_id INTEGER NOT NULL REFERENCES transaction_details (id) ON DELETE CASCADE,
+    quantity              INTEGER NOT NULL CHECK (quantity > 0),
+    amount                INTEGER NOT NULL
+);
+
+CREATE INDEX idx_refund_items_transaction_detail_id ON refund_items (transaction_detail_id);
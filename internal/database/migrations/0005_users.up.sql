@@ -0,0 +1,7 @@
+CREATE TABLE users (
+    id            SERIAL PRIMARY KEY,
+    username      VARCHAR(100) NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    role          VARCHAR(20) NOT NULL CHECK (role IN ('cashier', 'manager', 'admin')),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
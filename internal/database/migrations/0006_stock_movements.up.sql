@@ -0,0 +1,19 @@
+CREATE TABLE stock_movements (
+    id             SERIAL PRIMARY KEY,
+    product_id     INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    reason         VARCHAR(20) NOT NULL,
+    quantity_delta INTEGER NOT NULL,
+    balance_after  INTEGER NOT NULL,
+    actor          VARCHAR(100) NOT NULL DEFAULT '',
+    reference_id   INTEGER,
+    note           TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_stock_movements_product_id ON stock_movements (product_id, id);
+
+-- Opening balance so every product's history adds up to its current stock
+INSERT INTO stock_movements (product_id, reason, quantity_delta, balance_after, note)
+SELECT id, 'adjustment', stock, stock, 'opening balance'
+FROM products
+WHERE stock <> 0;
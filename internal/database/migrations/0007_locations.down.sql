@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS stock_transfer_items;
+DROP TABLE IF EXISTS stock_transfers;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS location_id;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS location_id;
+
+DROP TABLE IF EXISTS product_stocks;
+DROP TABLE IF EXISTS locations;
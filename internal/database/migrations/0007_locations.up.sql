@@ -0,0 +1,64 @@
+CREATE TABLE locations (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL UNIQUE,
+    type       VARCHAR(20) NOT NULL DEFAULT 'store' CHECK (type IN ('store', 'warehouse')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Everything that existed before locations lives at the first one
+INSERT INTO locations (name, type) VALUES ('Main Store', 'store');
+
+-- products.stock stays as the total across every location
+CREATE TABLE product_stocks (
+    product_id  INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    location_id INTEGER NOT NULL REFERENCES locations (id),
+    quantity    INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    PRIMARY KEY (product_id, location_id)
+);
+
+CREATE INDEX idx_product_stocks_location_id ON product_stocks (location_id);
+
+INSERT INTO product_stocks (product_id, location_id, quantity)
+SELECT id, (SELECT MIN(id) FROM locations), GREATEST(stock, 0)
+FROM products
+WHERE stock <> 0;
+
+ALTER TABLE stock_movements ADD COLUMN location_id INTEGER REFERENCES locations (id);
+UPDATE stock_movements SET location_id = (SELECT MIN(id) FROM locations);
+ALTER TABLE stock_movements ALTER COLUMN location_id SET NOT NULL;
+
+-- A location can't hold negative stock, so negative products were seeded with none
+-- above. Their total is brought up to match with an adjustment, keeping the history
+-- adding up to products.stock.
+INSERT INTO stock_movements (product_id, location_id, reason, quantity_delta, balance_after, note)
+SELECT id, (SELECT MIN(id) FROM locations), 'adjustment', -stock, 0, 'negative stock reset to zero by the locations migration'
+FROM products
+WHERE stock < 0;
+UPDATE products SET stock = 0 WHERE stock < 0;
+
+ALTER TABLE transactions ADD COLUMN location_id INTEGER REFERENCES locations (id);
+UPDATE transactions SET location_id = (SELECT MIN(id) FROM locations);
+ALTER TABLE transactions ALTER COLUMN location_id SET NOT NULL;
+
+CREATE TABLE stock_transfers (
+    id               SERIAL PRIMARY KEY,
+    from_location_id INTEGER NOT NULL REFERENCES locations (id),
+    to_location_id   INTEGER NOT NULL REFERENCES locations (id),
+    status           VARCHAR(20) NOT NULL CHECK (status IN ('in_transit', 'received', 'cancelled')),
+    note             TEXT NOT NULL DEFAULT '',
+    created_by       VARCHAR(100) NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at     TIMESTAMPTZ,
+    CHECK (from_location_id <> to_location_id)
+);
+
+CREATE INDEX idx_stock_transfers_status ON stock_transfers (status);
+
+CREATE TABLE stock_transfer_items (
+    id          SERIAL PRIMARY KEY,
+    transfer_id INTEGER NOT NULL REFERENCES stock_transfers (id) ON DELETE CASCADE,
+    product_id  INTEGER NOT NULL REFERENCES products (id),
+    quantity    INTEGER NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX idx_stock_transfer_items_transfer_id ON stock_transfer_items (transfer_id);
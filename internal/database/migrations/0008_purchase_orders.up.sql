@@ -0,0 +1,57 @@
+CREATE TABLE suppliers (
+    id           SERIAL PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL UNIQUE,
+    contact_name VARCHAR(255) NOT NULL DEFAULT '',
+    phone        VARCHAR(50) NOT NULL DEFAULT '',
+    email        VARCHAR(255) NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE purchase_orders (
+    id          SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers (id),
+    location_id INTEGER NOT NULL REFERENCES locations (id),
+    status      VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'sent', 'partially_received', 'closed')),
+    note        TEXT NOT NULL DEFAULT '',
+    created_by  VARCHAR(100) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at     TIMESTAMPTZ,
+    closed_at   TIMESTAMPTZ
+);
+
+CREATE INDEX idx_purchase_orders_status ON purchase_orders (status);
+CREATE INDEX idx_purchase_orders_supplier_id ON purchase_orders (supplier_id);
+
+CREATE TABLE purchase_order_lines (
+    id                SERIAL PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    product_id        INTEGER NOT NULL REFERENCES products (id),
+    quantity_ordered  INTEGER NOT NULL CHECK (quantity_ordered > 0),
+    quantity_received INTEGER NOT NULL DEFAULT 0 CHECK (quantity_received >= 0),
+    unit_cost         INTEGER NOT NULL CHECK (unit_cost >= 0),
+    UNIQUE (purchase_order_id, product_id),
+    CHECK (quantity_received <= quantity_ordered)
+);
+
+CREATE INDEX idx_purchase_order_lines_product_id ON purchase_order_lines (product_id);
+
+-- one row per delivery, the stock ledger references it
+CREATE TABLE goods_receipts (
+    id                SERIAL PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    note              TEXT NOT NULL DEFAULT '',
+    received_by       VARCHAR(100) NOT NULL DEFAULT '',
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_goods_receipts_purchase_order_id ON goods_receipts (purchase_order_id);
+
+CREATE TABLE goods_receipt_items (
+    id         SERIAL PRIMARY KEY,
+    receipt_id INTEGER NOT NULL REFERENCES goods_receipts (id) ON DELETE CASCADE,
+    line_id    INTEGER NOT NULL REFERENCES purchase_order_lines (id) ON DELETE CASCADE,
+    quantity   INTEGER NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX idx_goods_receipt_items_receipt_id ON goods_receipt_items (receipt_id);
@@ -0,0 +1,11 @@
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS discount_amount,
+    DROP COLUMN IF EXISTS gross_amount;
+
+ALTER TABLE transaction_details
+    DROP COLUMN IF EXISTS promotion_id,
+    DROP COLUMN IF EXISTS discount_amount,
+    DROP COLUMN IF EXISTS gross_amount,
+    DROP COLUMN IF EXISTS unit_price;
+
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,34 @@
+CREATE TABLE promotions (
+    id           SERIAL PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL,
+    type         VARCHAR(20) NOT NULL CHECK (type IN ('percentage', 'fixed', 'buy_x_get_y')),
+    value        INTEGER NOT NULL DEFAULT 0 CHECK (value >= 0),
+    buy_quantity INTEGER NOT NULL DEFAULT 0 CHECK (buy_quantity >= 0),
+    get_quantity INTEGER NOT NULL DEFAULT 0 CHECK (get_quantity >= 0),
+    product_id   INTEGER REFERENCES products (id) ON DELETE CASCADE,
+    category_id  INTEGER REFERENCES categories (id) ON DELETE CASCADE,
+    coupon_code  VARCHAR(50) UNIQUE,
+    starts_at    TIMESTAMPTZ,
+    ends_at      TIMESTAMPTZ,
+    active       BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (product_id IS NULL OR category_id IS NULL),
+    CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX idx_promotions_active ON promotions (active);
+
+-- subtotal stays the amount charged for the line, after its discount
+ALTER TABLE transaction_details
+    ADD COLUMN unit_price      INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN gross_amount    INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN discount_amount INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN promotion_id    INTEGER REFERENCES promotions (id) ON DELETE SET NULL;
+
+UPDATE transaction_details SET unit_price = COALESCE(subtotal / NULLIF(quantity, 0), 0), gross_amount = subtotal;
+
+ALTER TABLE transactions
+    ADD COLUMN gross_amount    INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN discount_amount INTEGER NOT NULL DEFAULT 0;
+
+UPDATE transactions SET gross_amount = total_amount;
@@ -0,0 +1,14 @@
+ALTER TABLE refund_items DROP COLUMN IF EXISTS tax_amount;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS tax_amount,
+    DROP COLUMN IF EXISTS tax_mode;
+
+ALTER TABLE transaction_details
+    DROP COLUMN IF EXISTS tax_amount,
+    DROP COLUMN IF EXISTS tax_rate,
+    DROP COLUMN IF EXISTS tax_class_id;
+
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class_id;
+
+DROP TABLE IF EXISTS tax_classes;
@@ -0,0 +1,22 @@
+-- rate in basis points, 1100 is 11%
+CREATE TABLE tax_classes (
+    id   SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    rate INTEGER NOT NULL CHECK (rate >= 0 AND rate <= 10000)
+);
+
+-- products without a tax class are taxed at the configured default rate
+ALTER TABLE products ADD COLUMN tax_class_id INTEGER REFERENCES tax_classes (id);
+
+-- the rate is copied onto each line so later rate changes don't rewrite history,
+-- older sales were recorded without tax
+ALTER TABLE transaction_details
+    ADD COLUMN tax_class_id INTEGER REFERENCES tax_classes (id),
+    ADD COLUMN tax_rate     INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN tax_amount   INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE transactions
+    ADD COLUMN tax_mode   VARCHAR(10) NOT NULL DEFAULT 'exclusive' CHECK (tax_mode IN ('inclusive', 'exclusive')),
+    ADD COLUMN tax_amount INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE refund_items ADD COLUMN tax_amount INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,14 @@
+-- responses to requests sent with an Idempotency-Key header, keys are per user
+CREATE TABLE idempotency_keys (
+    key          VARCHAR(255) NOT NULL,
+    username     VARCHAR(100) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code  INTEGER NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    body         BYTEA,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (key, username)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
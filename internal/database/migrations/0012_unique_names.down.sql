@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_categories_name_lower;
+DROP INDEX IF EXISTS idx_products_name_lower;
@@ -0,0 +1,25 @@
+-- names are unique ignoring case, backing the services' check against concurrent
+-- writes. Names already duplicated have to be renamed or merged by hand first, the
+-- migration stops and lists them rather than changing catalog data.
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(format('%s %L (ids %s)', kind, name, ids), ', ')
+    INTO duplicates
+    FROM (
+        SELECT 'product' AS kind, MIN(name) AS name, string_agg(id::text, ', ' ORDER BY id) AS ids
+        FROM products GROUP BY LOWER(name) HAVING COUNT(*) > 1
+        UNION ALL
+        SELECT 'category', MIN(name), string_agg(id::text, ', ' ORDER BY id)
+        FROM categories GROUP BY LOWER(name) HAVING COUNT(*) > 1
+    ) d;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'names must be unique ignoring case, rename these first: %', duplicates;
+    END IF;
+END
+$$;
+
+CREATE UNIQUE INDEX idx_products_name_lower ON products (LOWER(name));
+CREATE UNIQUE INDEX idx_categories_name_lower ON categories (LOWER(name));
@@ -0,0 +1,5 @@
+-- deleting a category that still has products fails in the database, so the
+-- restrict policy holds against products assigned while the delete runs
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_category_id_fkey;
+ALTER TABLE products ADD CONSTRAINT products_category_id_fkey
+    FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE RESTRICT;
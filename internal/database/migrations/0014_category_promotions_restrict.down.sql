@@ -0,0 +1,3 @@
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS promotions_category_id_fkey;
+ALTER TABLE promotions ADD CONSTRAINT promotions_category_id_fkey
+    FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+-- deleting a category took its promotions with it, leaving sales that used them
+-- without a promotion. The delete is refused now until the promotions are handled.
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS promotions_category_id_fkey;
+ALTER TABLE promotions ADD CONSTRAINT promotions_category_id_fkey
+    FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE RESTRICT;
@@ -3,10 +3,10 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"store-api-go/internal/httpx"
 	"store-api-go/internal/models"
+	"store-api-go/internal/pagination"
 	"store-api-go/internal/services"
-	"strconv"
-	"strings"
 )
 
 type CategoryHandler struct {
@@ -17,69 +17,26 @@ func NewCategoryHandler(service *services.CategoryService) *CategoryHandler {
 	return &CategoryHandler{service: service}
 }
 
-// handle /api/categories
-func (h *CategoryHandler) HandleCategories(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	switch r.Method {
-	case http.MethodGet:
-		h.GetAll(w, r)
-	case http.MethodPost:
-		h.Create(w, r)
-	default:
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Method not allowed",
-		})
-		// http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.CategoryFilter{
+		Name:   query.Get("name"),
+		Cursor: query.Get("cursor"),
 	}
-}
-
-// handle /api/categories/{id}
-func (h *CategoryHandler) HandleCategoryByID(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
 
-	// get id from path param
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/categories/")
-	id, err := strconv.Atoi(idStr)
+	sort, err := pagination.ParseSort(query.Get("sort"), models.CategorySortFields)
+	if err == nil {
+		filter.Sort = sort
+		filter.Limit, err = queryInt(r, "limit")
+	}
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Invalid ID",
-		})
-		// http.Error(w, "Invalid ID", http.StatusBadRequest)
+		httpx.WriteError(w, r, err)
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		h.GetByID(w, id)
-	case http.MethodPut:
-		h.Update(w, r, id)
-	case http.MethodDelete:
-		h.Delete(w, id)
-	default:
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Method not allowed",
-		})
-		// http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
-}
-
-func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	name := r.URL.Query().Get("name")
-	categories, err := h.service.GetAll(name)
+	categories, meta, err := h.service.GetAll(r.Context(), filter)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: err.Error(),
-		})
-		// http.Error(w, err.Error(), http.StatusInternalServerError)
+		httpx.WriteError(w, r, err)
 		return
 	}
 
@@ -88,31 +45,24 @@ func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 		Status:  "OK",
 		Message: "Categories retrieved",
 		Data:    categories,
+		Meta:    meta,
 	})
 	// json.NewEncoder(w).Encode(categories)
 }
 
 func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var newCategory models.Category
-	err := json.NewDecoder(r.Body).Decode(&newCategory)
+	err := decodeJSON(r, &newCategory)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Invalid request body",
-		})
+		httpx.WriteError(w, r, err)
 
 		// http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	err = h.service.Create(&newCategory)
+	err = h.service.Create(r.Context(), &newCategory)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: err.Error(),
-		})
+		httpx.WriteError(w, r, err)
 
 		// http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -128,14 +78,16 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// json.NewEncoder(w).Encode(newCategory)
 }
 
-func (h *CategoryHandler) GetByID(w http.ResponseWriter, id int) {
-	category, err := h.service.GetByID(id)
+func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: err.Error(),
-		})
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	category, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		httpx.WriteError(w, r, err)
 
 		// http.Error(w, err.Error(), http.StatusNotFound)
 		return
@@ -150,28 +102,53 @@ func (h *CategoryHandler) GetByID(w http.ResponseWriter, id int) {
 	// json.NewEncoder(w).Encode(category)
 }
 
-func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+func (h *CategoryHandler) GetProducts(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	filter, err := parseProductFilter(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	products, meta, err := h.service.GetProducts(r.Context(), id, filter)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Products retrieved",
+		Data:    products,
+		Meta:    meta,
+	})
+}
+
+func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
 	var categoryUpdate models.Category
-	err := json.NewDecoder(r.Body).Decode(&categoryUpdate)
+	err = decodeJSON(r, &categoryUpdate)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Invalid request body",
-		})
+		httpx.WriteError(w, r, err)
 
 		// http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	categoryUpdate.ID = id
-	err = h.service.Update(&categoryUpdate)
+	err = h.service.Update(r.Context(), &categoryUpdate)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: err.Error(),
-		})
+		httpx.WriteError(w, r, err)
 
 		// http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -185,14 +162,16 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request, id int)
 	// json.NewEncoder(w).Encode(categoryUpdate)
 }
 
-func (h *CategoryHandler) Delete(w http.ResponseWriter, id int) {
-	err := h.service.Delete(id)
+func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: err.Error(),
-		})
+		httpx.WriteError(w, r, err)
 
 		// http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
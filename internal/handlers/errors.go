@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"store-api-go/internal/apperrors"
+	"strconv"
+	"strings"
+)
+
+var (
+	errInvalidID   = apperrors.BadRequest("invalid_id", "Invalid ID")
+	errInvalidBody = apperrors.BadRequest("invalid_body", "Invalid request body")
+)
+
+// decodeJSON reads the request body into v, rejecting fields v doesn't have
+func decodeJSON(r *http.Request, v any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	// a well formed body with a field v doesn't have is a validation failure on that field
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if unquoted, err := strconv.Unquote(field); err == nil {
+			field = unquoted
+		}
+		var errs apperrors.FieldErrors
+		errs.Add(field, "unknown_field", "%s is not a known field", field)
+		return errs.Err()
+	}
+
+	return errInvalidBody
+}
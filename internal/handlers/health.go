@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"store-api-go/internal/database"
+	"store-api-go/internal/models"
+	"sync/atomic"
+	"time"
+)
+
+// HealthHandler serves the load balancer's probes. Liveness only says the process is
+// serving, readiness also needs the database and goes unready once shutdown starts.
+type HealthHandler struct {
+	db       *sql.DB
+	migrator *database.Migrator
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// NewHealthHandler takes a nil db and migrator for the in-memory storage, which is
+// always ready. timeout bounds the database checks of one readiness probe.
+func NewHealthHandler(db *sql.DB, migrator *database.Migrator, timeout time.Duration) *HealthHandler {
+	return &HealthHandler{db: db, migrator: migrator, timeout: timeout}
+}
+
+type DatabaseHealth struct {
+	Status             string `json:"status"`
+	Error              string `json:"error,omitempty"`
+	LatencyMs          int64  `json:"latency_ms"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	MaxOpenConnections int    `json:"max_open_connections"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDurationMs     int64  `json:"wait_duration_ms"`
+	MigrationVersion   int    `json:"migration_version"`
+	LatestMigration    int    `json:"latest_migration"`
+}
+
+type Readiness struct {
+	Draining bool            `json:"draining"`
+	Database *DatabaseHealth `json:"database,omitempty"`
+}
+
+// Drain makes readiness fail from now on, called when shutdown starts
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Alive",
+	})
+}
+
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	readiness := Readiness{Draining: h.draining.Load()}
+	if h.db != nil {
+		readiness.Database = h.checkDatabase(r.Context())
+	}
+
+	switch {
+	case readiness.Draining:
+		h.writeUnready(w, "shutting_down", "Shutting down", readiness)
+	case readiness.Database != nil && readiness.Database.Status != "up":
+		h.writeUnready(w, "database_unavailable", "Database unavailable", readiness)
+	default:
+		json.NewEncoder(w).Encode(models.Response{
+			Status:  "OK",
+			Message: "Ready",
+			Data:    readiness,
+		})
+	}
+}
+
+func (h *HealthHandler) checkDatabase(ctx context.Context) *DatabaseHealth {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	health := &DatabaseHealth{Status: "up"}
+	start := time.Now()
+	err := h.db.PingContext(ctx)
+	health.LatencyMs = time.Since(start).Milliseconds()
+	if err == nil && h.migrator != nil {
+		health.MigrationVersion, err = h.migrator.Version(ctx)
+		health.LatestMigration = h.migrator.Latest()
+	}
+	if err != nil {
+		health.Status = "down"
+		health.Error = err.Error()
+	}
+
+	stats := h.db.Stats()
+	health.OpenConnections = stats.OpenConnections
+	health.InUse = stats.InUse
+	health.Idle = stats.Idle
+	health.MaxOpenConnections = stats.MaxOpenConnections
+	health.WaitCount = stats.WaitCount
+	health.WaitDurationMs = stats.WaitDuration.Milliseconds()
+
+	return health
+}
+
+func (h *HealthHandler) writeUnready(w http.ResponseWriter, code string, message string, readiness Readiness) {
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "FAIL",
+		Code:    code,
+		Message: message,
+		Data:    readiness,
+	})
+}
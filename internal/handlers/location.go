@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"store-api-go/internal/httpx"
+	"store-api-go/internal/models"
+	"store-api-go/internal/services"
+)
+
+type LocationHandler struct {
+	service *services.LocationService
+}
+
+func NewLocationHandler(service *services.LocationService) *LocationHandler {
+	return &LocationHandler{service: service}
+}
+
+func (h *LocationHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	locations, err := h.service.GetAll(r.Context())
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Locations retrieved",
+		Data:    locations,
+	})
+}
+
+func (h *LocationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var location models.Location
+	err := decodeJSON(r, &location)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	err = h.service.Create(r.Context(), &location)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Location created",
+		Data:    location,
+	})
+}
+
+func (h *LocationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	location, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Location retrieved",
+		Data:    location,
+	})
+}
+
+func (h *LocationHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	var location models.Location
+	err = decodeJSON(r, &location)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	location.ID = id
+	err = h.service.Update(r.Context(), &location)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Location updated",
+		Data:    location,
+	})
+}
@@ -3,10 +3,12 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/httpx"
 	"store-api-go/internal/models"
+	"store-api-go/internal/pagination"
 	"store-api-go/internal/services"
 	"strconv"
-	"strings"
 )
 
 type ProductHandler struct {
@@ -17,65 +19,60 @@ func NewProductHandler(service *services.ProductService) *ProductHandler {
 	return &ProductHandler{service: service}
 }
 
-// handle /api/products
-func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	switch r.Method {
-	case http.MethodGet:
-		h.GetAll(w, r)
-	case http.MethodPost:
-		h.Create(w, r)
-	default:
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Method not allowed",
-		})
+// parseProductFilter reads the product list query params shared by
+// /api/products and /api/categories/{id}/products
+func parseProductFilter(r *http.Request) (models.ProductFilter, error) {
+	query := r.URL.Query()
+	filter := models.ProductFilter{
+		Name:   query.Get("name"),
+		Cursor: query.Get("cursor"),
 	}
-}
-
-// handle /api/categories/{id}
-func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
 
-	// get id from path param
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/products/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Invalid ID",
-		})
-		return
+	var err error
+	if filter.CategoryID, err = queryInt(r, "category_id"); err != nil {
+		return filter, err
+	}
+	if filter.MinPrice, err = queryInt(r, "min_price"); err != nil {
+		return filter, err
+	}
+	if filter.MaxPrice, err = queryInt(r, "max_price"); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = queryInt(r, "limit"); err != nil {
+		return filter, err
+	}
+	if filter.LocationID, err = queryInt(r, "location_id"); err != nil {
+		return filter, err
+	}
+	if stockByLocation := query.Get("stock_by_location"); stockByLocation != "" {
+		filter.StockByLocation, err = strconv.ParseBool(stockByLocation)
+		if err != nil {
+			return filter, apperrors.BadRequest("invalid_query", "Invalid stock_by_location")
+		}
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		h.GetByID(w, id)
-	case http.MethodPut:
-		h.Update(w, r, id)
-	case http.MethodDelete:
-		h.Delete(w, id)
-	default:
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Method not allowed",
-		})
+	if inStock := query.Get("in_stock"); inStock != "" {
+		value, err := strconv.ParseBool(inStock)
+		if err != nil {
+			return filter, apperrors.BadRequest("invalid_query", "Invalid in_stock")
+		}
+		filter.InStock = &value
 	}
+
+	filter.Sort, err = pagination.ParseSort(query.Get("sort"), models.ProductSortFields)
+	return filter, err
 }
 
 func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	name := r.URL.Query().Get("name")
-	products, err := h.service.GetAll(name)
+	filter, err := parseProductFilter(r)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: err.Error(),
-		})
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	products, meta, err := h.service.GetAll(r.Context(), filter)
+	if err != nil {
+		httpx.WriteError(w, r, err)
 		return
 	}
 
@@ -84,29 +81,22 @@ func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 		Status:  "OK",
 		Message: "Products retrieved",
 		Data:    products,
+		Meta:    meta,
 	})
 }
 
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var newProduct models.Product
-	err := json.NewDecoder(r.Body).Decode(&newProduct)
+	err := decodeJSON(r, &newProduct)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Invalid request body",
-		})
+		httpx.WriteError(w, r, err)
 
 		return
 	}
 
-	err = h.service.Create(&newProduct)
+	err = h.service.Create(r.Context(), &newProduct, actorName(r))
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: err.Error(),
-		})
+		httpx.WriteError(w, r, err)
 
 		return
 	}
@@ -120,14 +110,16 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 }
 
-func (h *ProductHandler) GetByID(w http.ResponseWriter, id int) {
-	product, err := h.service.GetByID(id)
+func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	product, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: err.Error(),
-		})
+		httpx.WriteError(w, r, err)
 		return
 	}
 
@@ -139,44 +131,44 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, id int) {
 
 }
 
-func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
-	var productUpdate models.Product
-	err := json.NewDecoder(r.Body).Decode(&productUpdate)
+func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	var productUpdate models.ProductUpdate
+	err = decodeJSON(r, &productUpdate)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Invalid request body",
-		})
+		httpx.WriteError(w, r, err)
 		return
 	}
 
 	productUpdate.ID = id
-	err = h.service.Update(&productUpdate)
+	err = h.service.Update(r.Context(), &productUpdate)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: err.Error(),
-		})
+		httpx.WriteError(w, r, err)
 		return
 	}
 
 	json.NewEncoder(w).Encode(models.Response{
 		Status:  "OK",
 		Message: "Product updated",
-		Data:    productUpdate,
+		Data:    productUpdate.Product,
 	})
 }
 
-func (h *ProductHandler) Delete(w http.ResponseWriter, id int) {
-	err := h.service.Delete(id)
+func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: err.Error(),
-		})
+		httpx.WriteError(w, r, err)
 		return
 	}
 
@@ -185,3 +177,90 @@ func (h *ProductHandler) Delete(w http.ResponseWriter, id int) {
 		Message: "Product deleted",
 	})
 }
+
+func (h *ProductHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	var request models.StockAdjustmentRequest
+	err = decodeJSON(r, &request)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	movement, err := h.service.AdjustStock(r.Context(), id, request, actorName(r))
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Stock adjusted",
+		Data:    movement,
+	})
+}
+
+func (h *ProductHandler) Stocktake(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	var request models.StocktakeRequest
+	err = decodeJSON(r, &request)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	movement, err := h.service.Stocktake(r.Context(), id, request, actorName(r))
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Stocktake recorded",
+		Data:    movement,
+	})
+}
+
+func (h *ProductHandler) GetStockHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	page, err := queryInt(r, "page")
+	var limit int
+	if err == nil {
+		limit, err = queryInt(r, "limit")
+	}
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	movements, meta, err := h.service.GetStockHistory(r.Context(), id, page, limit)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Stock history retrieved",
+		Data:    movements,
+		Meta:    meta,
+	})
+}
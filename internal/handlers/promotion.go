@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"store-api-go/internal/httpx"
+	"store-api-go/internal/models"
+	"store-api-go/internal/services"
+)
+
+type PromotionHandler struct {
+	service *services.PromotionService
+}
+
+func NewPromotionHandler(service *services.PromotionService) *PromotionHandler {
+	return &PromotionHandler{service: service}
+}
+
+func (h *PromotionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.service.GetAll(r.Context())
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Promotions retrieved",
+		Data:    promotions,
+	})
+}
+
+func (h *PromotionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var promotion models.Promotion
+	err := decodeJSON(r, &promotion)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	err = h.service.Create(r.Context(), &promotion)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Promotion created",
+		Data:    promotion,
+	})
+}
+
+func (h *PromotionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	promotion, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Promotion retrieved",
+		Data:    promotion,
+	})
+}
+
+func (h *PromotionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	var promotion models.Promotion
+	err = decodeJSON(r, &promotion)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	promotion.ID = id
+	err = h.service.Update(r.Context(), &promotion)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Promotion updated",
+		Data:    promotion,
+	})
+}
+
+func (h *PromotionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	err = h.service.Delete(r.Context(), id)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Promotion deleted",
+	})
+}
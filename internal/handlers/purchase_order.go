@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"store-api-go/internal/httpx"
+	"store-api-go/internal/models"
+	"store-api-go/internal/services"
+)
+
+type PurchaseOrderHandler struct {
+	service *services.PurchaseOrderService
+}
+
+func NewPurchaseOrderHandler(service *services.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{service: service}
+}
+
+func (h *PurchaseOrderHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	filter := models.PurchaseOrderFilter{Status: r.URL.Query().Get("status")}
+	var err error
+
+	if filter.SupplierID, err = queryInt(r, "supplier_id"); err == nil {
+		filter.Page, err = queryInt(r, "page")
+	}
+	if err == nil {
+		filter.Limit, err = queryInt(r, "limit")
+	}
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	orders, meta, err := h.service.GetAll(r.Context(), filter)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Purchase orders retrieved",
+		Data:    orders,
+		Meta:    meta,
+	})
+}
+
+func (h *PurchaseOrderHandler) OnOrder(w http.ResponseWriter, r *http.Request) {
+	products, err := h.service.OnOrder(r.Context())
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Products on order retrieved",
+		Data:    products,
+	})
+}
+
+func (h *PurchaseOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var request models.PurchaseOrder
+	err := decodeJSON(r, &request)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	order, err := h.service.Create(r.Context(), &request, actorName(r))
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Purchase order created",
+		Data:    order,
+	})
+}
+
+func (h *PurchaseOrderHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	order, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Purchase order retrieved",
+		Data:    order,
+	})
+}
+
+func (h *PurchaseOrderHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	var request models.ReceiveRequest
+	err = decodeJSON(r, &request)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	order, err := h.service.Receive(r.Context(), id, request, actorName(r))
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Goods received",
+		Data:    order,
+	})
+}
+
+func (h *PurchaseOrderHandler) Send(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "Purchase order sent", h.service.Send)
+}
+
+func (h *PurchaseOrderHandler) Close(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "Purchase order closed", h.service.Close)
+}
+
+// changeStatus runs a status change on the order in the path and writes the updated order
+func (h *PurchaseOrderHandler) changeStatus(w http.ResponseWriter, r *http.Request, message string, change func(ctx context.Context, id int) (*models.PurchaseOrder, error)) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	order, err := change(r.Context(), id)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: message,
+		Data:    order,
+	})
+}
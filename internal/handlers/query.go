@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"store-api-go/internal/apperrors"
+	"strconv"
+	"time"
+)
+
+// queryInt reads an optional integer query param, returning 0 when it is missing
+func queryInt(r *http.Request, name string) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, apperrors.BadRequest("invalid_query", "Invalid %s", name)
+	}
+
+	return number, nil
+}
+
+// queryDate reads an optional YYYY-MM-DD query param, returning "" when it is missing
+func queryDate(r *http.Request, name string) (string, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return "", nil
+	}
+
+	if _, err := time.Parse(dateLayout, value); err != nil {
+		return "", apperrors.BadRequest("invalid_date", "Invalid %s, expected YYYY-MM-DD", name)
+	}
+
+	return value, nil
+}
+
+// pathID reads the {id} path param
+func pathID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		return 0, errInvalidID
+	}
+
+	return id, nil
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"store-api-go/internal/httpx"
+	"store-api-go/internal/models"
+	"store-api-go/internal/services"
+)
+
+type TaxClassHandler struct {
+	service *services.TaxClassService
+}
+
+func NewTaxClassHandler(service *services.TaxClassService) *TaxClassHandler {
+	return &TaxClassHandler{service: service}
+}
+
+func (h *TaxClassHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	taxClasses, err := h.service.GetAll(r.Context())
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Tax classes retrieved",
+		Data:    taxClasses,
+	})
+}
+
+func (h *TaxClassHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var taxClass models.TaxClass
+	err := decodeJSON(r, &taxClass)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	err = h.service.Create(r.Context(), &taxClass)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Tax class created",
+		Data:    taxClass,
+	})
+}
+
+func (h *TaxClassHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	taxClass, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Tax class retrieved",
+		Data:    taxClass,
+	})
+}
+
+func (h *TaxClassHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	var taxClass models.TaxClass
+	err = decodeJSON(r, &taxClass)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	taxClass.ID = id
+	err = h.service.Update(r.Context(), &taxClass)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Tax class updated",
+		Data:    taxClass,
+	})
+}
+
+func (h *TaxClassHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	err = h.service.Delete(r.Context(), id)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Tax class deleted",
+	})
+}
@@ -2,69 +2,135 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/httpx"
 	"store-api-go/internal/models"
+	"store-api-go/internal/receipt"
 	"store-api-go/internal/services"
 	"time"
 )
 
+const dateLayout = "2006-01-02"
+
 type TransactionHandler struct {
-	service *services.TransactionService
+	service  *services.TransactionService
+	receipts *receipt.Renderer
+}
+
+func NewTransactionHandler(service *services.TransactionService, receipts *receipt.Renderer) *TransactionHandler {
+	return &TransactionHandler{service: service, receipts: receipts}
 }
 
-func NewTransactionHandler(service *services.TransactionService) *TransactionHandler {
-	return &TransactionHandler{service: service}
+func (h *TransactionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	var filter models.TransactionFilter
+	var err error
+
+	if filter.StartDate, err = queryDate(r, "start_date"); err == nil {
+		filter.EndDate, err = queryDate(r, "end_date")
+	}
+	if err == nil {
+		filter.MinAmount, err = queryInt(r, "min_amount")
+	}
+	if err == nil {
+		filter.MaxAmount, err = queryInt(r, "max_amount")
+	}
+	if err == nil {
+		filter.ProductID, err = queryInt(r, "product_id")
+	}
+	if err == nil {
+		filter.Page, err = queryInt(r, "page")
+	}
+	if err == nil {
+		filter.Limit, err = queryInt(r, "limit")
+	}
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	transactions, meta, err := h.service.GetAll(r.Context(), filter)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Transactions retrieved",
+		Data:    transactions,
+		Meta:    meta,
+	})
 }
 
-// /api/checkout
-func (h *TransactionHandler) HandleCheckout(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	switch r.Method {
-	case http.MethodPost:
-		h.Checkout(w, r)
-	default:
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Method not allowed",
-		})
+func (h *TransactionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	transaction, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
 	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Transaction retrieved",
+		Data:    transaction,
+	})
 }
 
-// /api/report/hari-ini
-func (h *TransactionHandler) HandleReportToday(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	switch r.Method {
-	case http.MethodGet:
-		h.ReportToday(w, r)
-	default:
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Method not allowed",
-		})
+// Receipt renders the receipt as text, html or escpos, text when no format is given
+func (h *TransactionHandler) Receipt(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = receipt.FormatText
+	}
+	if !receipt.IsValidFormat(format) {
+		httpx.WriteError(w, r, apperrors.BadRequest("invalid_format", "Invalid format, expected text, html or escpos"))
+		return
+	}
+
+	transaction, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	body, contentType, err := h.receipts.Render(format, transaction)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
 	}
+
+	w.Header().Set("Content-Type", contentType)
+	if format == receipt.FormatESCPOS {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"receipt-%d.bin\"", id))
+	}
+	w.Write(body)
 }
 
 func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 	var request models.CheckoutRequest
-	err := json.NewDecoder(r.Body).Decode(&request)
+	err := decodeJSON(r, &request)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: "Invalid request body",
-		})
+		httpx.WriteError(w, r, err)
 		return
 	}
 
-	transaction, err := h.service.Checkout(request.Items)
+	transaction, err := h.service.Checkout(r.Context(), request, actorName(r))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: err.Error(),
-		})
+		httpx.WriteError(w, r, err)
 		return
 	}
 
@@ -76,19 +142,107 @@ func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 
 }
 
+func (h *TransactionHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	var request models.RefundRequest
+	err = decodeJSON(r, &request)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	refund, err := h.service.Refund(r.Context(), transactionID, request, actorName(r))
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Refund created",
+		Data:    refund,
+	})
+}
+
 func (h *TransactionHandler) ReportToday(w http.ResponseWriter, r *http.Request) {
-	now := time.Now()
-	date := now.Format("2006-01-02")
-	reports, err := h.service.Report(date, date)
+	date := h.service.Today()
+	reports, err := h.service.Report(r.Context(), date, date)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.Response{
-			Status:  "FAIL",
-			Message: err.Error(),
-		})
+		httpx.WriteError(w, r, err)
 		return
 	}
 
 	json.NewEncoder(w).Encode(reports)
 
 }
+
+// parseReportRange reads start_date and end_date, missing dates default to today
+func parseReportRange(r *http.Request, today string) (string, string, error) {
+
+	startDate := r.URL.Query().Get("start_date")
+	if startDate == "" {
+		startDate = today
+	}
+	endDate := r.URL.Query().Get("end_date")
+	if endDate == "" {
+		endDate = today
+	}
+
+	start, err := time.Parse(dateLayout, startDate)
+	if err != nil {
+		return "", "", apperrors.BadRequest("invalid_date", "Invalid start_date, expected YYYY-MM-DD")
+	}
+
+	end, err := time.Parse(dateLayout, endDate)
+	if err != nil {
+		return "", "", apperrors.BadRequest("invalid_date", "Invalid end_date, expected YYYY-MM-DD")
+	}
+
+	if end.Before(start) {
+		return "", "", apperrors.BadRequest("invalid_date_range", "end_date must not be before start_date")
+	}
+
+	return startDate, endDate, nil
+}
+
+func (h *TransactionHandler) Report(w http.ResponseWriter, r *http.Request) {
+	startDate, endDate, err := parseReportRange(r, h.service.Today())
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	reports, err := h.service.Report(r.Context(), startDate, endDate)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(reports)
+}
+
+func (h *TransactionHandler) TaxReport(w http.ResponseWriter, r *http.Request) {
+	startDate, endDate, err := parseReportRange(r, h.service.Today())
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	report, err := h.service.TaxReport(r.Context(), startDate, endDate)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Tax report retrieved",
+		Data:    report,
+	})
+}
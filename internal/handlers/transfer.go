@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"store-api-go/internal/httpx"
+	"store-api-go/internal/models"
+	"store-api-go/internal/services"
+)
+
+type TransferHandler struct {
+	service *services.TransferService
+}
+
+func NewTransferHandler(service *services.TransferService) *TransferHandler {
+	return &TransferHandler{service: service}
+}
+
+func (h *TransferHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	transfers, err := h.service.GetAll(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Transfers retrieved",
+		Data:    transfers,
+	})
+}
+
+func (h *TransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var transfer models.StockTransfer
+	err := decodeJSON(r, &transfer)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	err = h.service.Create(r.Context(), &transfer, actorName(r))
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Transfer created",
+		Data:    transfer,
+	})
+}
+
+func (h *TransferHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	transfer, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Transfer retrieved",
+		Data:    transfer,
+	})
+}
+
+func (h *TransferHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	h.complete(w, r, "Transfer received", h.service.Receive)
+}
+
+func (h *TransferHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.complete(w, r, "Transfer cancelled", h.service.Cancel)
+}
+
+// complete runs a receive or cancel on the transfer in the path and writes the updated transfer
+func (h *TransferHandler) complete(w http.ResponseWriter, r *http.Request, message string, change func(ctx context.Context, id int, actor string) (*models.StockTransfer, error)) {
+	id, err := pathID(r)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	transfer, err := change(r.Context(), id, actorName(r))
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: message,
+		Data:    transfer,
+	})
+}
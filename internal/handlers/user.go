@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"store-api-go/internal/auth"
+	"store-api-go/internal/httpx"
+	"store-api-go/internal/models"
+	"store-api-go/internal/services"
+)
+
+type UserHandler struct {
+	service *services.UserService
+}
+
+func NewUserHandler(service *services.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+// actorName is the username recorded against changes made by the request
+func actorName(r *http.Request) string {
+	if claims := auth.ClaimsFromContext(r.Context()); claims != nil {
+		return claims.Username
+	}
+
+	return ""
+}
+
+func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var request models.LoginRequest
+	err := decodeJSON(r, &request)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	login, err := h.service.Login(r.Context(), request)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Login success",
+		Data:    login,
+	})
+}
+
+func (h *UserHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	users, err := h.service.GetAll(r.Context())
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "Users retrieved",
+		Data:    users,
+	})
+}
+
+func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var request models.CreateUserRequest
+	err := decodeJSON(r, &request)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	user, err := h.service.Create(r.Context(), request)
+	if err != nil {
+		httpx.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.Response{
+		Status:  "OK",
+		Message: "User created",
+		Data:    user,
+	})
+}
@@ -0,0 +1,46 @@
+// Package httpx writes the API's failure responses. Every layer answering a request
+// with an error, from the router to the handlers, goes through it so the body is the
+// same everywhere.
+package httpx
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+)
+
+// WriteError is the single mapping from errors to responses. Typed errors get their
+// kind's status, code and field errors, anything else is logged with the request's ID
+// and hidden behind a 500.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := apperrors.As(err)
+	if appErr == nil {
+		slog.ErrorContext(r.Context(), "Internal error", "method", r.Method, "path", r.URL.Path, "error", err)
+		WriteFail(w, http.StatusInternalServerError, apperrors.CodeInternal, "Internal server error")
+		return
+	}
+
+	write(w, apperrors.Status(appErr), models.Response{
+		Status:  "FAIL",
+		Code:    appErr.Code,
+		Message: err.Error(),
+		Errors:  appErr.Fields,
+	})
+}
+
+// WriteFail answers with a failure that has no apperrors kind, such as a 403 or 413
+func WriteFail(w http.ResponseWriter, status int, code string, message string) {
+	write(w, status, models.Response{
+		Status:  "FAIL",
+		Code:    code,
+		Message: message,
+	})
+}
+
+func write(w http.ResponseWriter, status int, response models.Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,52 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	var fields apperrors.FieldErrors
+	fields.Add("price", "invalid_min", "price must be at least 0")
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		want   models.Response
+	}{
+		{"not found", apperrors.NotFound("product_not_found", "Product not found"), http.StatusNotFound,
+			models.Response{Status: "FAIL", Code: "product_not_found", Message: "Product not found"}},
+		{"wrapped", fmt.Errorf("saving: %w", apperrors.Conflict("product_in_use", "Product is in use")), http.StatusConflict,
+			models.Response{Status: "FAIL", Code: "product_in_use", Message: "saving: Product is in use"}},
+		{"fields", fields.Err(), http.StatusUnprocessableEntity,
+			models.Response{Status: "FAIL", Code: "validation_failed", Message: "Validation failed", Errors: fields}},
+		{"untyped", errors.New("connection refused"), http.StatusInternalServerError,
+			models.Response{Status: "FAIL", Code: apperrors.CodeInternal, Message: "Internal server error"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			WriteError(w, httptest.NewRequest(http.MethodGet, "/", nil), test.err)
+
+			var got models.Response
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != test.status || w.Header().Get("Content-Type") != "application/json" {
+				t.Errorf("got %d %s, want %d application/json", w.Code, w.Header().Get("Content-Type"), test.status)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/auth"
+	"store-api-go/internal/httpx"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+	"time"
+)
+
+// Header carries the client's key, ReplayedHeader marks a response served from a previous request
+const (
+	Header         = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+const maxKeyLength = 255
+
+// bodies are read whole to hash them, larger ones are refused
+const maxBodySize = 1 << 20
+
+var (
+	errInvalidKey  = apperrors.BadRequest("invalid_idempotency_key", "Idempotency-Key must be at most %d characters", maxKeyLength)
+	errInvalidBody = apperrors.BadRequest("invalid_body", "Invalid request body")
+	errKeyReused   = apperrors.Validation("idempotency_key_reused", "Idempotency-Key was already used for a different request")
+	errInProgress  = apperrors.Conflict("request_in_progress", "A request with this Idempotency-Key is still in progress")
+)
+
+// Keys makes POST handlers safe to retry. The first request with a key runs the
+// handler and its response is kept for the retention window, repeats of the same
+// request get that response back without running the handler again.
+type Keys struct {
+	repo  repositories.IdempotencyRepository
+	ttl   time.Duration
+	lease time.Duration
+}
+
+// New keeps responses for ttl. A request holds its key for lease while it runs, a
+// retry after that takes the key over, as the request died without answering.
+func New(repo repositories.IdempotencyRepository, ttl time.Duration, lease time.Duration) *Keys {
+	return &Keys{repo: repo, ttl: ttl, lease: lease}
+}
+
+// Wrap runs next at most once per key. Requests without the header or other than POST
+// pass straight through. It must run after auth.Require, keys are per user.
+func (k *Keys) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if r.Method != http.MethodPost || key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxKeyLength {
+			httpx.WriteError(w, r, errInvalidKey)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpx.WriteFail(w, http.StatusRequestEntityTooLarge, "body_too_large", fmt.Sprintf("Request body must be at most %d bytes", tooLarge.Limit))
+			return
+		}
+		if err != nil {
+			httpx.WriteError(w, r, errInvalidBody)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		username := ""
+		if claims := auth.ClaimsFromContext(r.Context()); claims != nil {
+			username = claims.Username
+		}
+
+		record := models.IdempotencyKey{
+			Key:         key,
+			Username:    username,
+			RequestHash: requestHash(r, body),
+			ExpiresAt:   time.Now().Add(k.lease),
+		}
+		existing, err := k.repo.Reserve(r.Context(), &record)
+		if err != nil {
+			httpx.WriteError(w, r, err)
+			return
+		}
+
+		if existing != nil {
+			switch {
+			case existing.RequestHash != record.RequestHash:
+				httpx.WriteError(w, r, errKeyReused)
+			// the first request is still running, or died less than a lease ago
+			case existing.StatusCode == 0:
+				httpx.WriteError(w, r, errInProgress)
+			default:
+				w.Header().Set("Content-Type", existing.ContentType)
+				w.Header().Set(ReplayedHeader, "true")
+				w.WriteHeader(existing.StatusCode)
+				w.Write(existing.Body)
+			}
+			return
+		}
+
+		// the outcome is saved even when the client went away meanwhile
+		ctx := context.WithoutCancel(r.Context())
+
+		// a panicking handler releases its key rather than blocking retries for a lease
+		defer func() {
+			if p := recover(); p != nil {
+				k.release(ctx, &record)
+				panic(p)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(recorder, r)
+
+		// server errors may be transient, let the client retry them for real
+		if recorder.status >= http.StatusInternalServerError {
+			k.release(ctx, &record)
+			return
+		}
+
+		record.StatusCode = recorder.status
+		record.ContentType = w.Header().Get("Content-Type")
+		record.Body = recorder.body.Bytes()
+		record.ExpiresAt = time.Now().Add(k.ttl)
+		if err := k.repo.Complete(ctx, &record); err != nil {
+			slog.ErrorContext(ctx, "Failed to save idempotent response", "error", err)
+		}
+	}
+}
+
+// release drops the reservation so the key can be retried
+func (k *Keys) release(ctx context.Context, record *models.IdempotencyKey) {
+	if err := k.repo.Release(ctx, record); err != nil {
+		slog.ErrorContext(ctx, "Failed to release idempotency key", "error", err)
+	}
+}
+
+// Purge deletes expired keys every interval until ctx is cancelled
+func (k *Keys) Purge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := k.repo.DeleteExpired(ctx, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "Failed to purge idempotency keys", "error", err)
+			}
+		}
+	}
+}
+
+// requestHash identifies the request a key was first used for
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes the response through while keeping a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+
+	return rec.ResponseWriter.Write(b)
+}
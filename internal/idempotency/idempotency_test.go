@@ -0,0 +1,110 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories/memory"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLease = 50 * time.Millisecond
+
+// newTestKeys wraps a handler counting its runs
+func newTestKeys() (*memory.IdempotencyRepo, http.HandlerFunc, *int) {
+	repo := memory.NewIdempotencyRepo(memory.NewStore())
+	runs := 0
+	handler := New(repo, time.Hour, testLease).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		runs++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"status":"OK"}`))
+	})
+
+	return repo, handler, &runs
+}
+
+func post(handler http.HandlerFunc, key string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/checkout", strings.NewReader(body))
+	r.Header.Set(Header, key)
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	return w
+}
+
+func TestReplay(t *testing.T) {
+	_, handler, runs := newTestKeys()
+
+	first := post(handler, "k1", `{"items":[]}`)
+	second := post(handler, "k1", `{"items":[]}`)
+	if *runs != 1 {
+		t.Fatalf("handler ran %d times, want 1", *runs)
+	}
+	if second.Code != http.StatusCreated || second.Header().Get(ReplayedHeader) != "true" || second.Body.String() != first.Body.String() {
+		t.Errorf("repeat got %d %q, want the replayed first response", second.Code, second.Body.String())
+	}
+
+	// completed responses are kept past the lease
+	time.Sleep(2 * testLease)
+	if post(handler, "k1", `{"items":[]}`); *runs != 1 {
+		t.Errorf("handler ran again after the lease, responses must last the ttl")
+	}
+
+	if w := post(handler, "k1", `{"items":[1]}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("other body with the same key got %d, want 422", w.Code)
+	}
+}
+
+// TestCrashedRequest leaves a reservation behind as a request killed mid-flight does,
+// retries are refused while its lease runs and go through once it ran out
+func TestCrashedRequest(t *testing.T) {
+	repo, handler, runs := newTestKeys()
+
+	body := `{"items":[]}`
+	crashed := httptest.NewRequest(http.MethodPost, "/api/checkout", strings.NewReader(body))
+	existing, err := repo.Reserve(context.Background(), &models.IdempotencyKey{
+		Key:         "k1",
+		RequestHash: requestHash(crashed, []byte(body)),
+		ExpiresAt:   time.Now().Add(testLease),
+	})
+	if err != nil || existing != nil {
+		t.Fatalf("reserve got %v %v", existing, err)
+	}
+
+	w := post(handler, "k1", body)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "request_in_progress") {
+		t.Errorf("retry during the lease got %d %s, want 409 request_in_progress", w.Code, w.Body.String())
+	}
+
+	time.Sleep(2 * testLease)
+	if w := post(handler, "k1", body); w.Code != http.StatusCreated || *runs != 1 {
+		t.Errorf("retry after the lease got %d with %d runs, want 201 from running the handler", w.Code, *runs)
+	}
+}
+
+func TestReleaseOnServerError(t *testing.T) {
+	repo := memory.NewIdempotencyRepo(memory.NewStore())
+	status := http.StatusInternalServerError
+	handler := New(repo, time.Hour, time.Hour).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+
+	post(handler, "k1", `{}`)
+	status = http.StatusCreated
+	if w := post(handler, "k1", `{}`); w.Code != http.StatusCreated {
+		t.Errorf("retry after a 500 got %d, want the handler to run again", w.Code)
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	_, handler, runs := newTestKeys()
+
+	w := post(handler, "k1", strings.Repeat("a", maxBodySize+1))
+	if w.Code != http.StatusRequestEntityTooLarge || *runs != 0 {
+		t.Errorf("got %d with %d runs, want 413 without running the handler", w.Code, *runs)
+	}
+}
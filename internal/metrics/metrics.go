@@ -0,0 +1,143 @@
+// Package metrics exposes the API's Prometheus metrics: requests per route, the
+// database pool and checkout volume.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "store"
+
+// unmatchedRoute labels requests that matched no route, keeping raw paths out of the labels
+const unmatchedRoute = "unmatched"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time to serve HTTP requests, by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	checkouts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checkouts_total",
+		Help:      "Completed checkouts.",
+	})
+
+	checkoutAmount = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checkout_amount_total",
+		Help:      "Sum of the total amount of completed checkouts, in the smallest currency unit.",
+	})
+
+	checkoutOutOfStock = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checkout_out_of_stock_total",
+		Help:      "Checkouts rejected because a product was out of stock.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		checkouts, checkoutAmount, checkoutOutOfStock,
+	)
+}
+
+// RegisterDB exports the pool's sql.DBStats, labelled with name
+func RegisterDB(db *sql.DB, name string) {
+	registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+func CheckoutCompleted(amount int) {
+	checkouts.Inc()
+	checkoutAmount.Add(float64(amount))
+}
+
+func CheckoutOutOfStock() {
+	checkoutOutOfStock.Inc()
+}
+
+// statusRecorder remembers the status written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+
+	return rec.ResponseWriter.Write(b)
+}
+
+// Middleware records every request under its route pattern. It reads r.Pattern,
+// which the ServeMux sets on the request it is given, so it has to be the last
+// middleware before the mux.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(rec, r)
+
+		route := unmatchedRoute
+		if r.Pattern != "" {
+			// patterns are "METHOD /path", the method has its own label
+			_, path, found := strings.Cut(r.Pattern, " ")
+			if !found {
+				path = r.Pattern
+			}
+			route = path
+		}
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		method := methodLabel(r.Method)
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(rec.status)).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// methodLabel folds methods no route uses into one label, clients can send any method
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	}
+
+	return "OTHER"
+}
@@ -1,7 +1,28 @@
 package models
 
+import "store-api-go/internal/pagination"
+
 type Category struct {
 	ID          int    `json:"id"`
-	Name        string `json:"name"`
+	Name        string `json:"name" validate:"required,maxlen=255"`
 	Description string `json:"description"`
 }
+
+// Fields categories can be sorted by
+var CategorySortFields = []string{"id", "name"}
+
+// SortKey returns the value of a sort field, integers as int64
+func (c Category) SortKey(field string) any {
+	if field == "name" {
+		return c.Name
+	}
+
+	return int64(c.ID)
+}
+
+type CategoryFilter struct {
+	Name   string
+	Sort   []pagination.SortField
+	Limit  int
+	Cursor string
+}
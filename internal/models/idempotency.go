@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// IdempotencyKey remembers the response to the first request a user sent with a key.
+// StatusCode is 0 while that request is still being handled, ExpiresAt then ends its
+// lease on the key rather than the retention of the response.
+type IdempotencyKey struct {
+	Key         string
+	Username    string
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Location types
+const (
+	LocationTypeStore     = "store"
+	LocationTypeWarehouse = "warehouse"
+)
+
+type Location struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProductStock is a product's stock level at one location
+type ProductStock struct {
+	LocationID   int    `json:"location_id"`
+	LocationName string `json:"location_name"`
+	Quantity     int    `json:"quantity"`
+}
+
+// Stock transfer statuses. Stock leaves the source when the transfer is created
+// and only arrives at the destination once it is received.
+const (
+	TransferStatusInTransit = "in_transit"
+	TransferStatusReceived  = "received"
+	TransferStatusCancelled = "cancelled"
+)
+
+type StockTransfer struct {
+	ID             int                 `json:"id"`
+	FromLocationID int                 `json:"from_location_id"`
+	ToLocationID   int                 `json:"to_location_id"`
+	Status         string              `json:"status"`
+	Note           string              `json:"note,omitempty"`
+	CreatedBy      string              `json:"created_by"`
+	CreatedAt      time.Time           `json:"created_at"`
+	CompletedAt    *time.Time          `json:"completed_at"`
+	Items          []StockTransferItem `json:"items,omitempty"`
+}
+
+type StockTransferItem struct {
+	ID          int    `json:"id"`
+	TransferID  int    `json:"transfer_id"`
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name,omitempty"`
+	Quantity    int    `json:"quantity"`
+}
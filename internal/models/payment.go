@@ -0,0 +1,21 @@
+package models
+
+// Supported payment methods
+const (
+	PaymentMethodCash = "cash"
+	PaymentMethodCard = "card"
+	PaymentMethodQRIS = "qris"
+)
+
+type Payment struct {
+	ID            int    `json:"id"`
+	TransactionID int    `json:"transaction_id"`
+	Method        string `json:"method"`
+	Amount        int    `json:"amount"`
+	Reference     string `json:"reference,omitempty"`
+}
+
+type PaymentMethodRevenue struct {
+	Method string `json:"method"`
+	Amount int    `json:"amount"`
+}
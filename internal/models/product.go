@@ -1,8 +1,56 @@
 package models
 
+import "store-api-go/internal/pagination"
+
+// Product.Stock is the total across all locations, or the stock at one location
+// when the list is filtered by location. Stocks holds the per location breakdown.
 type Product struct {
-	ID    int    `json:"id"`
-	Name  string `json:"name"`
-	Price int    `json:"price"`
-	Stock int    `json:"stock"`
+	ID           int            `json:"id"`
+	Name         string         `json:"name" validate:"required,maxlen=255"`
+	Price        int            `json:"price" validate:"min=0,max=2147483647"`
+	Stock        int            `json:"stock" validate:"min=0,max=2147483647"`
+	CategoryID   *int           `json:"category_id"`
+	CategoryName string         `json:"category_name,omitempty"`
+	TaxClassID   *int           `json:"tax_class_id"`
+	Stocks       []ProductStock `json:"stocks,omitempty"`
+}
+
+// ProductUpdate is the body of a product update. Stock only changes through stock
+// adjustments, it is accepted when it matches the stored stock, as in a product
+// sent back from a GET, and rejected otherwise.
+type ProductUpdate struct {
+	Product
+	Stock *int `json:"stock"`
+}
+
+// Fields products can be sorted by
+var ProductSortFields = []string{"id", "name", "price", "stock"}
+
+// SortKey returns the value of a sort field, integers as int64
+func (p Product) SortKey(field string) any {
+	switch field {
+	case "name":
+		return p.Name
+	case "price":
+		return int64(p.Price)
+	case "stock":
+		return int64(p.Stock)
+	default:
+		return int64(p.ID)
+	}
+}
+
+// ProductFilter narrows the product list, zero values are ignored
+type ProductFilter struct {
+	Name       string
+	CategoryID int
+	MinPrice   int
+	MaxPrice   int
+	InStock    *bool
+	LocationID int
+	// include the per location breakdown
+	StockByLocation bool
+	Sort            []pagination.SortField
+	Limit           int
+	Cursor          string
 }
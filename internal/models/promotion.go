@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Promotion types. Percentage takes Value percent off the line, fixed takes Value off
+// every unit, buy_x_get_y makes GetQuantity of every BuyQuantity+GetQuantity units free.
+const (
+	PromotionPercentage = "percentage"
+	PromotionFixed      = "fixed"
+	PromotionBuyXGetY   = "buy_x_get_y"
+)
+
+// Promotion applies to one product, every product of a category, or everything when
+// neither is set. Promotions with a coupon code only apply when the code is given at
+// checkout, StartsAt and EndsAt optionally limit when the promotion runs. Active is nil
+// only in requests that leave it out, the service fills it in before saving.
+type Promotion struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	Value       int        `json:"value"`
+	BuyQuantity int        `json:"buy_quantity,omitempty"`
+	GetQuantity int        `json:"get_quantity,omitempty"`
+	ProductID   *int       `json:"product_id"`
+	CategoryID  *int       `json:"category_id"`
+	CouponCode  string     `json:"coupon_code,omitempty"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	Active      *bool      `json:"active"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// RunsAt reports whether the promotion is switched on and inside its time window
+func (p Promotion) RunsAt(now time.Time) bool {
+	if p.Active != nil && !*p.Active {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !now.Before(*p.EndsAt) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// Purchase order statuses. Lines can only be received once the order is sent, and an
+// order closes by itself when every line is fully received.
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderSent              = "sent"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderClosed            = "closed"
+)
+
+type PurchaseOrder struct {
+	ID           int                 `json:"id"`
+	SupplierID   int                 `json:"supplier_id"`
+	SupplierName string              `json:"supplier_name,omitempty"`
+	LocationID   int                 `json:"location_id"`
+	Status       string              `json:"status"`
+	Note         string              `json:"note,omitempty"`
+	TotalCost    int                 `json:"total_cost"`
+	CreatedBy    string              `json:"created_by"`
+	CreatedAt    time.Time           `json:"created_at"`
+	SentAt       *time.Time          `json:"sent_at"`
+	ClosedAt     *time.Time          `json:"closed_at"`
+	Lines        []PurchaseOrderLine `json:"lines,omitempty"`
+	Receipts     []GoodsReceipt      `json:"receipts,omitempty"`
+}
+
+type PurchaseOrderLine struct {
+	ID               int    `json:"id"`
+	PurchaseOrderID  int    `json:"purchase_order_id"`
+	ProductID        int    `json:"product_id"`
+	ProductName      string `json:"product_name,omitempty"`
+	QuantityOrdered  int    `json:"quantity_ordered"`
+	QuantityReceived int    `json:"quantity_received"`
+	UnitCost         int    `json:"unit_cost"`
+}
+
+// PurchaseOrderFilter narrows the purchase order list, zero values are ignored
+type PurchaseOrderFilter struct {
+	Status     string
+	SupplierID int
+	Page       int
+	Limit      int
+}
+
+// GoodsReceipt is one delivery against a purchase order
+type GoodsReceipt struct {
+	ID              int                `json:"id"`
+	PurchaseOrderID int                `json:"purchase_order_id"`
+	Note            string             `json:"note,omitempty"`
+	ReceivedBy      string             `json:"received_by"`
+	CreatedAt       time.Time          `json:"created_at"`
+	Items           []GoodsReceiptItem `json:"items"`
+}
+
+type GoodsReceiptItem struct {
+	ID        int `json:"id"`
+	ReceiptID int `json:"receipt_id"`
+	LineID    int `json:"line_id"`
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// ReceiveRequest books a delivery, or everything still outstanding when Items is empty
+type ReceiveRequest struct {
+	Items []GoodsReceiptItem `json:"items"`
+	Note  string             `json:"note"`
+}
+
+// OnOrderProduct is how much of a product is ordered from suppliers but not received yet
+type OnOrderProduct struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type Refund struct {
+	ID            int          `json:"id"`
+	TransactionID int          `json:"transaction_id"`
+	Amount        int          `json:"amount"`
+	Reason        string       `json:"reason,omitempty"`
+	Restock       bool         `json:"restock"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Items         []RefundItem `json:"items"`
+}
+
+type RefundItem struct {
+	ID                  int `json:"id"`
+	RefundID            int `json:"refund_id"`
+	TransactionDetailID int `json:"transaction_detail_id"`
+	ProductID           int `json:"product_id"`
+	Quantity            int `json:"quantity"`
+	Amount              int `json:"amount"`
+	TaxAmount           int `json:"tax_amount"`
+}
+
+// RefundRequest refunds the listed lines, or everything not yet refunded when Items is empty
+type RefundRequest struct {
+	Items   []RefundItem `json:"items"`
+	Restock bool         `json:"restock"`
+	Reason  string       `json:"reason"`
+}
@@ -1,7 +1,27 @@
 package models
 
+import "store-api-go/internal/apperrors"
+
+// Response.Code is set on failures, a machine readable error code such as
+// "product_not_found" or "out_of_stock". Errors lists the failed fields of a
+// validation failure.
 type Response struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
-	Data    any    `json:"data,omitempty"`
+	Status  string                 `json:"status"`
+	Code    string                 `json:"code,omitempty"`
+	Message string                 `json:"message"`
+	Errors  []apperrors.FieldError `json:"errors,omitempty"`
+	Data    any                    `json:"data,omitempty"`
+	Meta    any                    `json:"meta,omitempty"`
+}
+
+type PageMeta struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+	Total int `json:"total"`
+}
+
+type CursorMeta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
 }
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Why a product's stock changed at a location
+const (
+	StockReasonSale        = "sale"
+	StockReasonRefund      = "refund"
+	StockReasonAdjustment  = "adjustment"
+	StockReasonReceipt     = "receipt"
+	StockReasonStocktake   = "stocktake"
+	StockReasonTransferOut = "transfer_out"
+	StockReasonTransferIn  = "transfer_in"
+)
+
+type StockMovement struct {
+	ID            int       `json:"id"`
+	ProductID     int       `json:"product_id"`
+	LocationID    int       `json:"location_id"`
+	Reason        string    `json:"reason"`
+	QuantityDelta int       `json:"quantity_delta"`
+	BalanceAfter  int       `json:"balance_after"`
+	Actor         string    `json:"actor"`
+	ReferenceID   *int      `json:"reference_id"`
+	Note          string    `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StockAdjustmentRequest adds (or with a negative quantity removes) stock
+type StockAdjustmentRequest struct {
+	LocationID int    `json:"location_id"`
+	Quantity   int    `json:"quantity"`
+	Reason     string `json:"reason"`
+	Note       string `json:"note"`
+}
+
+// StocktakeRequest sets the stock to what was physically counted
+type StocktakeRequest struct {
+	LocationID      int    `json:"location_id"`
+	CountedQuantity int    `json:"counted_quantity"`
+	Note            string `json:"note"`
+}
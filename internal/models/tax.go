@@ -0,0 +1,79 @@
+package models
+
+import "sort"
+
+// Tax pricing modes. Inclusive prices already contain the tax, exclusive prices have
+// the tax added on top at checkout.
+const (
+	TaxInclusive = "inclusive"
+	TaxExclusive = "exclusive"
+)
+
+// TaxClass.Rate is in basis points, 1100 is 11%
+type TaxClass struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Rate int    `json:"rate"`
+}
+
+// TaxReportLine sums the sales and refunds taxed at one rate of one tax class.
+// TaxClassID is nil for products taxed at the store default rate. Taxable amounts
+// exclude the tax.
+type TaxReportLine struct {
+	TaxClassID            *int   `json:"tax_class_id"`
+	TaxClassName          string `json:"tax_class_name,omitempty"`
+	Rate                  int    `json:"rate"`
+	TaxableAmount         int    `json:"taxable_amount"`
+	TaxAmount             int    `json:"tax_amount"`
+	RefundedTaxableAmount int    `json:"refunded_taxable_amount"`
+	RefundedTaxAmount     int    `json:"refunded_tax_amount"`
+}
+
+// TaxReport.NetTaxAmount is the tax collected less the tax refunded in the period
+type TaxReport struct {
+	StartDate             string          `json:"start_date"`
+	EndDate               string          `json:"end_date"`
+	TaxableAmount         int             `json:"taxable_amount"`
+	TaxAmount             int             `json:"tax_amount"`
+	RefundedTaxableAmount int             `json:"refunded_taxable_amount"`
+	RefundedTaxAmount     int             `json:"refunded_tax_amount"`
+	NetTaxAmount          int             `json:"net_tax_amount"`
+	Lines                 []TaxReportLine `json:"lines"`
+}
+
+// Add sums line into the report's line for the same tax class and rate and into the totals
+func (r *TaxReport) Add(line TaxReportLine) {
+	r.TaxableAmount += line.TaxableAmount
+	r.TaxAmount += line.TaxAmount
+	r.RefundedTaxableAmount += line.RefundedTaxableAmount
+	r.RefundedTaxAmount += line.RefundedTaxAmount
+	r.NetTaxAmount = r.TaxAmount - r.RefundedTaxAmount
+
+	for i := range r.Lines {
+		existing := &r.Lines[i]
+		if existing.Rate == line.Rate && sameID(existing.TaxClassID, line.TaxClassID) {
+			existing.TaxableAmount += line.TaxableAmount
+			existing.TaxAmount += line.TaxAmount
+			existing.RefundedTaxableAmount += line.RefundedTaxableAmount
+			existing.RefundedTaxAmount += line.RefundedTaxAmount
+			return
+		}
+	}
+
+	r.Lines = append(r.Lines, line)
+	sort.Slice(r.Lines, func(i, j int) bool {
+		a, b := r.Lines[i].TaxClassID, r.Lines[j].TaxClassID
+		if !sameID(a, b) {
+			return a == nil || (b != nil && *a < *b)
+		}
+		return r.Lines[i].Rate < r.Lines[j].Rate
+	})
+}
+
+func sameID(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
@@ -2,37 +2,89 @@ package models
 
 import "time"
 
+// Transaction.TotalAmount is what the customer pays, GrossAmount less DiscountAmount,
+// plus TaxAmount when TaxMode is exclusive. Inclusive totals already contain the tax.
 type Transaction struct {
-	ID          int                 `json:"id"`
-	TotalAmount int                 `json:"total_amount"`
-	CreatedAt   time.Time           `json:"created_at"`
-	Details     []TransactionDetail `json:"details"`
+	ID             int                 `json:"id"`
+	LocationID     int                 `json:"location_id"`
+	GrossAmount    int                 `json:"gross_amount"`
+	DiscountAmount int                 `json:"discount_amount"`
+	TaxMode        string              `json:"tax_mode"`
+	TaxAmount      int                 `json:"tax_amount"`
+	TotalAmount    int                 `json:"total_amount"`
+	PaidAmount     int                 `json:"paid_amount"`
+	ChangeAmount   int                 `json:"change_amount"`
+	CreatedAt      time.Time           `json:"created_at"`
+	Details        []TransactionDetail `json:"details,omitempty"`
+	Payments       []Payment           `json:"payments,omitempty"`
 }
 
+// TransactionFilter narrows the transaction history, zero values are ignored
+type TransactionFilter struct {
+	StartDate string
+	EndDate   string
+	MinAmount int
+	MaxAmount int
+	ProductID int
+	Page      int
+	Limit     int
+}
+
+// TransactionDetail.Subtotal is the line's GrossAmount less the promotion's DiscountAmount,
+// plus TaxAmount with exclusive pricing. TaxRate is in basis points, TaxClassID is nil
+// when the store default rate applied.
 type TransactionDetail struct {
-	ID            int    `json:"id"`
-	TransactionID int    `json:"transaction_id"`
-	ProductID     int    `json:"product_id"`
-	ProductName   string `json:"product_name,omitempty"`
-	Quantity      int    `json:"quantity"`
-	Subtotal      int    `json:"subtotal"`
+	ID             int    `json:"id"`
+	TransactionID  int    `json:"transaction_id"`
+	ProductID      int    `json:"product_id"`
+	ProductName    string `json:"product_name,omitempty"`
+	Quantity       int    `json:"quantity"`
+	UnitPrice      int    `json:"unit_price"`
+	GrossAmount    int    `json:"gross_amount"`
+	DiscountAmount int    `json:"discount_amount"`
+	PromotionID    *int   `json:"promotion_id"`
+	PromotionName  string `json:"promotion_name,omitempty"`
+	TaxClassID     *int   `json:"tax_class_id"`
+	TaxRate        int    `json:"tax_rate"`
+	TaxAmount      int    `json:"tax_amount"`
+	Subtotal       int    `json:"subtotal"`
+	// only known at checkout, used to match category promotions
+	CategoryID *int `json:"-"`
+
+	RefundedQuantity  int `json:"refunded_quantity"`
+	RefundedAmount    int `json:"refunded_amount"`
+	RefundedTaxAmount int `json:"refunded_tax_amount"`
 }
 
 type CheckoutItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID int `json:"product_id" validate:"min=1"`
+	Quantity  int `json:"quantity" validate:"min=1,max=2147483647"`
 }
 
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
+	LocationID  int            `json:"location_id" validate:"min=0"`
+	Items       []CheckoutItem `json:"items" validate:"required"`
+	Payments    []Payment      `json:"payments"`
+	CouponCodes []string       `json:"coupon_codes"`
 }
 
 type BestProduct struct {
 	Name    string `json:"nama"`
 	SoldQty int    `json:"qty_terjual"`
 }
+
+// ReportResponse.NetSales is what customers paid, GrossSales less TotalDiscount plus any
+// exclusive tax. TotalTax is the tax contained in NetSales, TotalRevenue is NetSales
+// less TotalRefund
 type ReportResponse struct {
+	GrossSales        int         `json:"gross_sales"`
+	TotalDiscount     int         `json:"total_discount"`
+	NetSales          int         `json:"net_sales"`
+	TotalTax          int         `json:"total_tax"`
 	TotalRevenue      int         `json:"total_revenue"`
+	TotalRefund       int         `json:"total_refund"`
 	TotalTransaction  int         `json:"total_transaksi"`
 	BestSellerProduct BestProduct `json:"produk_terlaris"`
+
+	RevenueByPaymentMethod []PaymentMethodRevenue `json:"revenue_by_payment_method"`
 }
@@ -0,0 +1,173 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"store-api-go/internal/apperrors"
+	"strings"
+)
+
+// Page size limits for keyset paginated lists
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+var ErrInvalidCursor = apperrors.BadRequest("invalid_cursor", "Invalid cursor")
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort reads a sort spec such as "price,-name". Every field must be in allowed,
+// and "id" is appended as the final tiebreaker so keys are always unique.
+func ParseSort(raw string, allowed []string) ([]SortField, error) {
+	fields := make([]SortField, 0)
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !slices.Contains(allowed, field.Field) {
+			return nil, apperrors.BadRequest("invalid_sort", "Invalid sort field %q, allowed: %s", field.Field, strings.Join(allowed, ", "))
+		}
+		if seen[field.Field] {
+			return nil, apperrors.BadRequest("invalid_sort", "Duplicate sort field %q", field.Field)
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+
+	if !seen["id"] {
+		fields = append(fields, SortField{Field: "id"})
+	}
+
+	return fields, nil
+}
+
+// String turns the sort back into its "price,-name,id" form
+func String(sort []SortField) string {
+	parts := make([]string, len(sort))
+	for i, field := range sort {
+		parts[i] = field.Field
+		if field.Desc {
+			parts[i] = "-" + field.Field
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+type cursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+// EncodeCursor makes an opaque cursor from the sort key values of the last row on a page
+func EncodeCursor(sort []SortField, values []any) string {
+	data, _ := json.Marshal(cursor{Sort: String(sort), Values: values})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor returns the key values stored in the cursor. Integers come back as int64.
+// The cursor must have been issued for the same sort.
+func DecodeCursor(sort []SortField, raw string) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var decoded cursor
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if decoded.Sort != String(sort) || len(decoded.Values) != len(sort) {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, value := range decoded.Values {
+		switch v := value.(type) {
+		case json.Number:
+			number, err := v.Int64()
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			decoded.Values[i] = number
+		case string:
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return decoded.Values, nil
+}
+
+// Keyed is a row that can report its sort key values
+type Keyed interface {
+	SortKey(field string) any
+}
+
+// Trim drops the extra row fetched past the limit and returns the cursor pointing
+// after the last row kept, or "" when there is no next page
+func Trim[T Keyed](rows []T, sort []SortField, limit int) ([]T, string) {
+	if len(rows) <= limit {
+		return rows, ""
+	}
+
+	rows = rows[:limit]
+	return rows, EncodeCursor(sort, Key(rows[len(rows)-1], sort))
+}
+
+// Key returns the row's values for every sort field
+func Key(row Keyed, sort []SortField) []any {
+	values := make([]any, len(sort))
+	for i, field := range sort {
+		values[i] = row.SortKey(field.Field)
+	}
+
+	return values
+}
+
+// Compare orders two key tuples under the sort, for backends that sort in Go.
+// Values must be int64 or string.
+func Compare(sort []SortField, a []any, b []any) int {
+	for i, field := range sort {
+		result := compareValue(a[i], b[i])
+		if field.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+func compareValue(a any, b any) int {
+	switch av := a.(type) {
+	case int64:
+		bv, _ := b.(int64)
+		switch {
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		}
+	case string:
+		bv, _ := b.(string)
+		return strings.Compare(av, bv)
+	}
+
+	return 0
+}
@@ -0,0 +1,152 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"net/http"
+	"reflect"
+	"slices"
+	"store-api-go/internal/apperrors"
+	"testing"
+)
+
+type row struct {
+	id    int64
+	price int64
+	name  string
+}
+
+func (r row) SortKey(field string) any {
+	switch field {
+	case "price":
+		return r.price
+	case "name":
+		return r.name
+	}
+
+	return r.id
+}
+
+func mustParseSort(t *testing.T, raw string) []SortField {
+	t.Helper()
+
+	sort, err := ParseSort(raw, []string{"id", "price", "name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sort
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"", "id"},
+		{"price", "price,id"},
+		{" -price , name ", "-price,name,id"},
+		{"-id", "-id"},
+		{"name,-id", "name,-id"},
+	}
+
+	for _, test := range tests {
+		if got := String(mustParseSort(t, test.raw)); got != test.want {
+			t.Errorf("ParseSort(%q) = %q, want %q", test.raw, got, test.want)
+		}
+	}
+
+	for _, raw := range []string{"stock", "price,-price"} {
+		if _, err := ParseSort(raw, []string{"id", "price", "name"}); apperrors.Status(err) != http.StatusBadRequest {
+			t.Errorf("ParseSort(%q) got %v, want a 400", raw, err)
+		}
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	sort := mustParseSort(t, "-price,name")
+	values := []any{int64(1500), "Coffee", int64(42)}
+
+	decoded, err := DecodeCursor(sort, EncodeCursor(sort, values))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, values) {
+		t.Errorf("decoded %#v, want %#v", decoded, values)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	sort := mustParseSort(t, "price")
+	valid := EncodeCursor(sort, []any{int64(1500), int64(42)})
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"truncated", valid[:len(valid)-3]},
+		{"not json", encode("garbage")},
+		{"other sort", EncodeCursor(mustParseSort(t, "-price"), []any{int64(1500), int64(42)})},
+		{"missing values", encode(`{"s":"price,id","v":[1500]}`)},
+		{"float value", encode(`{"s":"price,id","v":[1.5,42]}`)},
+		{"object value", encode(`{"s":"price,id","v":[{},42]}`)},
+		{"null values", encode(`{"s":"price,id","v":null}`)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := DecodeCursor(sort, test.cursor)
+			if appErr := apperrors.As(err); appErr == nil || appErr.Code != "invalid_cursor" || apperrors.Status(err) != http.StatusBadRequest {
+				t.Errorf("got %v, want a 400 invalid_cursor", err)
+			}
+		})
+	}
+}
+
+// TestPagesWithTies walks rows sharing a price page by page, the id tiebreak must
+// return every row exactly once
+func TestPagesWithTies(t *testing.T) {
+	rows := []row{
+		{1, 500, "a"}, {2, 300, "b"}, {3, 500, "c"}, {4, 300, "d"},
+		{5, 500, "e"}, {6, 100, "f"}, {7, 500, "g"},
+	}
+
+	for _, raw := range []string{"price", "-price", "-price,-id"} {
+		t.Run(raw, func(t *testing.T) {
+			sort := mustParseSort(t, raw)
+			sorted := slices.Clone(rows)
+			slices.SortFunc(sorted, func(a row, b row) int { return Compare(sort, Key(a, sort), Key(b, sort)) })
+
+			var seen []row
+			cursor := ""
+			for pages := 0; ; pages++ {
+				if pages > len(rows) {
+					t.Fatal("paging did not end")
+				}
+
+				remaining := sorted
+				if cursor != "" {
+					after, err := DecodeCursor(sort, cursor)
+					if err != nil {
+						t.Fatal(err)
+					}
+					remaining = slices.DeleteFunc(slices.Clone(sorted), func(r row) bool {
+						return Compare(sort, Key(r, sort), after) <= 0
+					})
+				}
+
+				page, next := Trim(remaining[:min(len(remaining), 3)], sort, 2)
+				seen = append(seen, page...)
+				if next == "" {
+					break
+				}
+				cursor = next
+			}
+
+			if !slices.Equal(seen, sorted) {
+				t.Errorf("pages returned %v, want %v", seen, sorted)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package receipt
+
+import "bytes"
+
+// ESC/POS commands understood by practically every thermal receipt printer
+var (
+	escposInit = []byte{0x1b, '@'}         // ESC @, reset the printer
+	escposFeed = []byte{0x1b, 'd', 4}      // ESC d 4, feed past the cutter
+	escposCut  = []byte{0x1d, 'V', 'B', 0} // GS V B 0, partial cut
+)
+
+// escpos wraps a text receipt in the commands to print and cut it. Printers use a
+// single byte code page, so anything outside printable ASCII is printed as '?'.
+func escpos(text string) []byte {
+	var buf bytes.Buffer
+	buf.Write(escposInit)
+
+	for _, r := range text {
+		switch {
+		case r == '\n':
+			buf.WriteByte('\n')
+		case r < 0x20:
+		case r > 0x7e:
+			buf.WriteByte('?')
+		default:
+			buf.WriteByte(byte(r))
+		}
+	}
+
+	buf.Write(escposFeed)
+	buf.Write(escposCut)
+
+	return buf.Bytes()
+}
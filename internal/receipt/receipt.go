@@ -0,0 +1,198 @@
+package receipt
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"store-api-go/internal/models"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// Receipt formats
+const (
+	FormatText   = "text"
+	FormatHTML   = "html"
+	FormatESCPOS = "escpos"
+)
+
+// Width is the number of characters on a line of a text receipt, what 80mm thermal printers fit
+const Width = 42
+
+const (
+	textTemplate = "receipt.txt.tmpl"
+	htmlTemplate = "receipt.html.tmpl"
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+// Store is printed on the header and footer of every receipt. Address and Footer may
+// span several lines, separated by newlines or a literal \n.
+type Store struct {
+	Name    string
+	Address string
+	Footer  string
+}
+
+// Data is what the templates are executed with
+type Data struct {
+	Store       Store
+	Transaction *models.Transaction
+}
+
+type Renderer struct {
+	store Store
+	text  *texttemplate.Template
+	html  *htmltemplate.Template
+}
+
+// New parses the receipt templates once. A receipt.txt.tmpl or receipt.html.tmpl in
+// templateDir replaces the embedded default of the same name. Dates are printed in location.
+func New(store Store, templateDir string, location *time.Location) (*Renderer, error) {
+	funcs := templateFuncs(location)
+
+	textSource, err := loadTemplate(templateDir, textTemplate)
+	if err != nil {
+		return nil, err
+	}
+	text, err := texttemplate.New(textTemplate).Funcs(funcs).Parse(textSource)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", textTemplate, err)
+	}
+
+	htmlSource, err := loadTemplate(templateDir, htmlTemplate)
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.New(htmlTemplate).Funcs(htmltemplate.FuncMap(funcs)).Parse(htmlSource)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", htmlTemplate, err)
+	}
+
+	return &Renderer{store: store, text: text, html: html}, nil
+}
+
+func loadTemplate(templateDir string, name string) (string, error) {
+	if templateDir != "" {
+		source, err := os.ReadFile(filepath.Join(templateDir, name))
+		if err == nil {
+			return string(source), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	source, err := templateFiles.ReadFile("templates/" + name)
+	return string(source), err
+}
+
+func IsValidFormat(format string) bool {
+	return format == FormatText || format == FormatHTML || format == FormatESCPOS
+}
+
+// Render returns the transaction's receipt in format along with its content type
+func (r *Renderer) Render(format string, transaction *models.Transaction) ([]byte, string, error) {
+	data := Data{Store: r.store, Transaction: transaction}
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatText:
+		if err := r.text.Execute(&buf, data); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "text/plain; charset=utf-8", nil
+	case FormatHTML:
+		if err := r.html.Execute(&buf, data); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "text/html; charset=utf-8", nil
+	case FormatESCPOS:
+		if err := r.text.Execute(&buf, data); err != nil {
+			return nil, "", err
+		}
+		return escpos(buf.String()), "application/octet-stream", nil
+	default:
+		return nil, "", fmt.Errorf("unknown receipt format %q", format)
+	}
+}
+
+func templateFuncs(location *time.Location) map[string]any {
+	return map[string]any{
+		"money":   money,
+		"rate":    rate,
+		"date":    func(t time.Time) string { return t.In(location).Format("2006-01-02 15:04") },
+		"lines":   lines,
+		"upper":   strings.ToUpper,
+		"center":  center,
+		"fit":     func(s string) string { return truncate(s, Width) },
+		"columns": columns,
+		"rule":    func() string { return strings.Repeat("-", Width) },
+	}
+}
+
+// money formats an amount with dots between the thousands, 1250000 is 1.250.000
+func money(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+
+	digits := strconv.Itoa(amount)
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digit)
+	}
+
+	return sign + b.String()
+}
+
+// rate formats basis points as a percentage, 1100 is 11%
+func rate(basisPoints int) string {
+	return strconv.FormatFloat(float64(basisPoints)/100, 'f', -1, 64) + "%"
+}
+
+// lines splits on newlines and literal \n, which is how multi line values come out of env files
+func lines(s string) []string {
+	s = strings.TrimSpace(strings.ReplaceAll(s, `\n`, "\n"))
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, "\n")
+}
+
+func center(s string) string {
+	s = truncate(strings.TrimSpace(s), Width)
+	return strings.Repeat(" ", (Width-utf8.RuneCountInString(s))/2) + s
+}
+
+// columns puts left and right on one line of Width characters, shortening left when both don't fit
+func columns(left string, right string) string {
+	room := Width - utf8.RuneCountInString(right) - 1
+	left = truncate(left, room)
+
+	return left + strings.Repeat(" ", Width-utf8.RuneCountInString(left)-utf8.RuneCountInString(right)) + right
+}
+
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	return string([]rune(s)[:n])
+}
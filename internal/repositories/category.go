@@ -1,9 +1,16 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"store-api-go/internal/apperrors"
 	"store-api-go/internal/models"
+	"store-api-go/internal/pagination"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type CategoryRepo struct {
@@ -14,19 +21,38 @@ func NewCategoryRepo(db *sql.DB) *CategoryRepo {
 	return &CategoryRepo{db: db}
 }
 
-func (repo *CategoryRepo) GetAll(name string) ([]models.Category, error) {
+var categoryColumns = map[string]string{
+	"id":   "id",
+	"name": "name",
+}
+
+func (repo *CategoryRepo) GetAll(ctx context.Context, filter models.CategoryFilter) ([]models.Category, string, error) {
 	query := "SELECT id, name, description FROM categories"
 
-	// data type can be any type --> use interface. but the interface can be multiple??
+	var conditions []string
 	var args []interface{}
-	if name != "" {
-		query += " WHERE name ILIKE $1"
-		args = append(args, "%"+name+"%")
+	if filter.Name != "" {
+		args = append(args, "%"+filter.Name+"%")
+		conditions = append(conditions, fmt.Sprintf("name ILIKE $%d", len(args)))
+	}
+	if filter.Cursor != "" {
+		after, err := pagination.DecodeCursor(filter.Sort, filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		conditions = append(conditions, keysetCondition(filter.Sort, categoryColumns, after, &args))
 	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// fetch one extra row to know whether there is a next page
+	args = append(args, filter.Limit+1)
+	query += orderBy(filter.Sort, categoryColumns) + fmt.Sprintf(" LIMIT $%d", len(args))
 
-	rows, err := repo.db.Query(query, args...) // adding argument to query
+	rows, err := repo.db.QueryContext(ctx, query, args...) // adding argument to query
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
@@ -35,28 +61,32 @@ func (repo *CategoryRepo) GetAll(name string) ([]models.Category, error) {
 		var category models.Category
 		err := rows.Scan(&category.ID, &category.Name, &category.Description)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		categories = append(categories, category)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
 
-	return categories, nil
+	categories, next := pagination.Trim(categories, filter.Sort, filter.Limit)
+	return categories, next, nil
 }
 
-func (repo *CategoryRepo) Create(category *models.Category) error {
+func (repo *CategoryRepo) Create(ctx context.Context, category *models.Category) error {
 	query := "INSERT INTO categories (name, description) VALUES ($1, $2) RETURNING id"
-	err := repo.db.QueryRow(query, category.Name, category.Description).Scan(&category.ID)
+	err := repo.db.QueryRowContext(ctx, query, category.Name, category.Description).Scan(&category.ID)
 
-	return err
+	return nameTaken(err, "idx_categories_name_lower")
 }
 
-func (repo *CategoryRepo) GetByID(id int) (*models.Category, error) {
+func (repo *CategoryRepo) GetByID(ctx context.Context, id int) (*models.Category, error) {
 	query := "SELECT id, name, description FROM categories WHERE id = $1"
 
 	var category models.Category
-	err := repo.db.QueryRow(query, id).Scan(&category.ID, &category.Name, &category.Description)
+	err := repo.db.QueryRowContext(ctx, query, id).Scan(&category.ID, &category.Name, &category.Description)
 	if err == sql.ErrNoRows {
-		return nil, errors.New("Category not found")
+		return nil, apperrors.NotFound("category_not_found", "Category not found")
 	}
 	if err != nil {
 		return nil, err
@@ -65,11 +95,11 @@ func (repo *CategoryRepo) GetByID(id int) (*models.Category, error) {
 	return &category, nil
 }
 
-func (repo *CategoryRepo) Update(category *models.Category) error {
+func (repo *CategoryRepo) Update(ctx context.Context, category *models.Category) error {
 	query := "UPDATE categories SET name = $1, description = $2 WHERE id = $3"
-	result, err := repo.db.Exec(query, category.Name, category.Description, category.ID)
+	result, err := repo.db.ExecContext(ctx, query, category.Name, category.Description, category.ID)
 	if err != nil {
-		return err
+		return nameTaken(err, "idx_categories_name_lower")
 	}
 
 	rows, err := result.RowsAffected()
@@ -78,15 +108,74 @@ func (repo *CategoryRepo) Update(category *models.Category) error {
 	}
 
 	if rows == 0 {
-		return errors.New("Category not found")
+		return apperrors.NotFound("category_not_found", "Category not found")
 	}
 
 	return nil
 }
 
-func (repo *CategoryRepo) Delete(id int) error {
-	query := "DELETE FROM categories WHERE id = $1"
-	result, err := repo.db.Exec(query, id)
+func (repo *CategoryRepo) Delete(ctx context.Context, id int) error {
+	return deleteCategory(ctx, repo.db, id)
+}
+
+func (repo *CategoryRepo) NameExists(ctx context.Context, name string, excludeID int) (bool, error) {
+	var exists bool
+	err := repo.db.QueryRowContext(
+		ctx, "SELECT EXISTS (SELECT 1 FROM categories WHERE LOWER(name) = LOWER($1) AND id <> $2)", name, excludeID,
+	).Scan(&exists)
+
+	return exists, err
+}
+
+func (repo *CategoryRepo) DeleteWithProducts(ctx context.Context, id int) error {
+	dbTransaction, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.Rollback()
+
+	_, err = dbTransaction.ExecContext(ctx, "DELETE FROM products WHERE category_id = $1", id)
+	if err != nil {
+		return productInUse(err)
+	}
+	if err := deleteCategory(ctx, dbTransaction, id); err != nil {
+		return err
+	}
+
+	return dbTransaction.Commit()
+}
+
+func (repo *CategoryRepo) DeleteDetachingProducts(ctx context.Context, id int) error {
+	dbTransaction, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.Rollback()
+
+	_, err = dbTransaction.ExecContext(ctx, "UPDATE products SET category_id = NULL WHERE category_id = $1", id)
+	if err != nil {
+		return err
+	}
+	if err := deleteCategory(ctx, dbTransaction, id); err != nil {
+		return err
+	}
+
+	return dbTransaction.Commit()
+}
+
+// deleteCategory deletes the row, turning the foreign keys still pointing at it into
+// conflicts naming what references the category
+func deleteCategory(ctx context.Context, db interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}, id int) error {
+	result, err := db.ExecContext(ctx, "DELETE FROM categories WHERE id = $1", id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		if pgErr.TableName == "promotions" {
+			return apperrors.Conflict("category_has_promotions", "Category is still referenced by promotions")
+		}
+		return apperrors.Conflict("category_in_use", "Category is still referenced by products")
+	}
 	if err != nil {
 		return err
 	}
@@ -96,8 +185,8 @@ func (repo *CategoryRepo) Delete(id int) error {
 	}
 
 	if rows == 0 {
-		return errors.New("Category not found")
+		return apperrors.NotFound("category_not_found", "Category not found")
 	}
 
-	return err
+	return nil
 }
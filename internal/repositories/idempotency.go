@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"time"
+)
+
+type IdempotencyRepo struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepo(db *sql.DB) *IdempotencyRepo {
+	return &IdempotencyRepo{db: db}
+}
+
+func (repo *IdempotencyRepo) Reserve(ctx context.Context, record *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	// the conflict update only takes over expired keys, no row comes back when the
+	// key is live
+	err := repo.db.QueryRowContext(
+		ctx, `INSERT INTO idempotency_keys (key, username, request_hash, expires_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key, username) DO UPDATE
+			SET request_hash = EXCLUDED.request_hash, status_code = 0, content_type = '', body = NULL,
+				created_at = now(), expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= now()
+		RETURNING created_at`,
+		record.Key, record.Username, record.RequestHash, record.ExpiresAt,
+	).Scan(&record.CreatedAt)
+	if err == nil {
+		return nil, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	existing := models.IdempotencyKey{Key: record.Key, Username: record.Username}
+	err = repo.db.QueryRowContext(
+		ctx, `SELECT request_hash, status_code, content_type, COALESCE(body, ''), created_at, expires_at
+		FROM idempotency_keys WHERE key = $1 AND username = $2`, record.Key, record.Username,
+	).Scan(&existing.RequestHash, &existing.StatusCode, &existing.ContentType, &existing.Body,
+		&existing.CreatedAt, &existing.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.Conflict("idempotency_key_released", "Idempotency key was released, retry the request")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &existing, nil
+}
+
+func (repo *IdempotencyRepo) Complete(ctx context.Context, record *models.IdempotencyKey) error {
+	_, err := repo.db.ExecContext(
+		ctx, `UPDATE idempotency_keys SET status_code = $1, content_type = $2, body = $3, expires_at = $4
+		WHERE key = $5 AND username = $6 AND created_at = $7 AND status_code = 0`,
+		record.StatusCode, record.ContentType, record.Body, record.ExpiresAt, record.Key, record.Username, record.CreatedAt,
+	)
+
+	return err
+}
+
+func (repo *IdempotencyRepo) Release(ctx context.Context, record *models.IdempotencyKey) error {
+	_, err := repo.db.ExecContext(
+		ctx, "DELETE FROM idempotency_keys WHERE key = $1 AND username = $2 AND created_at = $3 AND status_code = 0",
+		record.Key, record.Username, record.CreatedAt,
+	)
+
+	return err
+}
+
+func (repo *IdempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	result, err := repo.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= $1", now)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"fmt"
+	"store-api-go/internal/pagination"
+	"strings"
+)
+
+// keysetCondition builds the WHERE clause selecting rows after the cursor values, e.g.
+// for "price,-name,id": (price > $1) OR (price = $1 AND name < $2) OR (price = $1 AND name = $2 AND id > $3)
+func keysetCondition(sort []pagination.SortField, columns map[string]string, values []any, args *[]any) string {
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		*args = append(*args, value)
+		placeholders[i] = fmt.Sprintf("$%d", len(*args))
+	}
+
+	alternatives := make([]string, len(sort))
+	for i, field := range sort {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, columns[sort[j].Field]+" = "+placeholders[j])
+		}
+
+		operator := " > "
+		if field.Desc {
+			operator = " < "
+		}
+		parts = append(parts, columns[field.Field]+operator+placeholders[i])
+
+		alternatives[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+func orderBy(sort []pagination.SortField, columns map[string]string) string {
+	parts := make([]string, len(sort))
+	for i, field := range sort {
+		parts[i] = columns[field.Field]
+		if field.Desc {
+			parts[i] += " DESC"
+		}
+	}
+
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
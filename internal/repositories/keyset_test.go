@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"reflect"
+	"store-api-go/internal/pagination"
+	"testing"
+)
+
+var testColumns = map[string]string{"id": "p.id", "price": "p.price", "name": "p.name"}
+
+func TestKeysetCondition(t *testing.T) {
+	tests := []struct {
+		sort []pagination.SortField
+		want string
+	}{
+		{
+			[]pagination.SortField{{Field: "id"}},
+			"((p.id > $2))",
+		},
+		{
+			[]pagination.SortField{{Field: "id", Desc: true}},
+			"((p.id < $2))",
+		},
+		{
+			[]pagination.SortField{{Field: "price"}, {Field: "name", Desc: true}, {Field: "id"}},
+			"((p.price > $2) OR (p.price = $2 AND p.name < $3) OR (p.price = $2 AND p.name = $3 AND p.id > $4))",
+		},
+	}
+
+	for _, test := range tests {
+		values := []any{int64(1500), "Coffee", int64(42)}[3-len(test.sort):]
+		// the placeholders continue after the filters already in args
+		args := []any{"%co%"}
+
+		if got := keysetCondition(test.sort, testColumns, values, &args); got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+		if want := append([]any{"%co%"}, values...); !reflect.DeepEqual(args, want) {
+			t.Errorf("args %v, want %v", args, want)
+		}
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	sort := []pagination.SortField{{Field: "price", Desc: true}, {Field: "name"}, {Field: "id"}}
+	if got, want := orderBy(sort, testColumns), " ORDER BY p.price DESC, p.name, p.id"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
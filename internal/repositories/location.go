@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type LocationRepo struct {
+	db *sql.DB
+}
+
+func NewLocationRepo(db *sql.DB) *LocationRepo {
+	return &LocationRepo{db: db}
+}
+
+func (repo *LocationRepo) GetAll(ctx context.Context) ([]models.Location, error) {
+	rows, err := repo.db.QueryContext(ctx, "SELECT id, name, type, created_at FROM locations ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := make([]models.Location, 0)
+	for rows.Next() {
+		var location models.Location
+		if err := rows.Scan(&location.ID, &location.Name, &location.Type, &location.CreatedAt); err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+
+	return locations, rows.Err()
+}
+
+func (repo *LocationRepo) GetByID(ctx context.Context, id int) (*models.Location, error) {
+	var location models.Location
+	err := repo.db.QueryRowContext(
+		ctx, "SELECT id, name, type, created_at FROM locations WHERE id = $1", id,
+	).Scan(&location.ID, &location.Name, &location.Type, &location.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NotFound("location_not_found", "Location not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &location, nil
+}
+
+func (repo *LocationRepo) Create(ctx context.Context, location *models.Location) error {
+	query := "INSERT INTO locations (name, type) VALUES ($1, $2) RETURNING id, created_at"
+	err := repo.db.QueryRowContext(ctx, query, location.Name, location.Type).Scan(&location.ID, &location.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return apperrors.Conflict("location_name_taken", "Location name already exists")
+	}
+
+	return err
+}
+
+func (repo *LocationRepo) Update(ctx context.Context, location *models.Location) error {
+	query := "UPDATE locations SET name = $1, type = $2 WHERE id = $3 RETURNING created_at"
+	err := repo.db.QueryRowContext(ctx, query, location.Name, location.Type, location.ID).Scan(&location.CreatedAt)
+	if err == sql.ErrNoRows {
+		return apperrors.NotFound("location_not_found", "Location not found")
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return apperrors.Conflict("location_name_taken", "Location name already exists")
+	}
+
+	return err
+}
@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"context"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+	"strings"
+)
+
+type CategoryRepo struct {
+	store *Store
+}
+
+func NewCategoryRepo(store *Store) *CategoryRepo {
+	return &CategoryRepo{store: store}
+}
+
+func (repo *CategoryRepo) GetAll(ctx context.Context, filter models.CategoryFilter) ([]models.Category, string, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	categories := make([]models.Category, 0, len(repo.store.categories))
+	for _, category := range repo.store.categories {
+		if filter.Name != "" && !strings.Contains(strings.ToLower(category.Name), strings.ToLower(filter.Name)) {
+			continue
+		}
+		categories = append(categories, category)
+	}
+
+	return page(categories, filter.Sort, filter.Cursor, filter.Limit)
+}
+
+func (repo *CategoryRepo) Create(ctx context.Context, category *models.Category) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	// same guarantee as the unique index on LOWER(categories.name)
+	if repo.store.categoryNameTaken(category.Name, 0) {
+		return repositories.NameTaken()
+	}
+
+	repo.store.lastCategoryID++
+	category.ID = repo.store.lastCategoryID
+	repo.store.categories[category.ID] = *category
+
+	return nil
+}
+
+func (repo *CategoryRepo) GetByID(ctx context.Context, id int) (*models.Category, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	category, exists := repo.store.categories[id]
+	if !exists {
+		return nil, apperrors.NotFound("category_not_found", "Category not found")
+	}
+
+	return &category, nil
+}
+
+func (repo *CategoryRepo) Update(ctx context.Context, category *models.Category) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, exists := repo.store.categories[category.ID]; !exists {
+		return apperrors.NotFound("category_not_found", "Category not found")
+	}
+	if repo.store.categoryNameTaken(category.Name, category.ID) {
+		return repositories.NameTaken()
+	}
+	repo.store.categories[category.ID] = *category
+
+	return nil
+}
+
+func (repo *CategoryRepo) Delete(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if err := repo.store.checkCategoryDelete(id, true); err != nil {
+		return err
+	}
+	delete(repo.store.categories, id)
+
+	return nil
+}
+
+func (repo *CategoryRepo) NameExists(ctx context.Context, name string, excludeID int) (bool, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	return repo.store.categoryNameTaken(name, excludeID), nil
+}
+
+// categoryNameTaken reports whether a category other than excludeID has the name,
+// ignoring case. Callers must hold the store lock.
+func (s *Store) categoryNameTaken(name string, excludeID int) bool {
+	for _, category := range s.categories {
+		if category.ID != excludeID && strings.EqualFold(category.Name, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkCategoryDelete mirrors the foreign keys refusing to delete a category that
+// promotions, or products when they keep pointing at it, still reference. Callers
+// must hold the store lock.
+func (s *Store) checkCategoryDelete(id int, productsKeepCategory bool) error {
+	if _, exists := s.categories[id]; !exists {
+		return apperrors.NotFound("category_not_found", "Category not found")
+	}
+
+	if productsKeepCategory {
+		for _, product := range s.products {
+			if product.CategoryID != nil && *product.CategoryID == id {
+				return apperrors.Conflict("category_in_use", "Category is still referenced by products")
+			}
+		}
+	}
+	for _, promotion := range s.promotions {
+		if promotion.CategoryID != nil && *promotion.CategoryID == id {
+			return apperrors.Conflict("category_has_promotions", "Category is still referenced by promotions")
+		}
+	}
+
+	return nil
+}
+
+func (repo *CategoryRepo) DeleteWithProducts(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if err := repo.store.checkCategoryDelete(id, false); err != nil {
+		return err
+	}
+
+	var productIDs []int
+	for _, product := range repo.store.products {
+		if product.CategoryID != nil && *product.CategoryID == id {
+			if repo.store.productHasSales(product.ID) {
+				return apperrors.Conflict("product_in_use", "Product is referenced by transactions")
+			}
+			productIDs = append(productIDs, product.ID)
+		}
+	}
+
+	for _, productID := range productIDs {
+		repo.store.deleteProduct(productID)
+	}
+	delete(repo.store.categories, id)
+
+	return nil
+}
+
+func (repo *CategoryRepo) DeleteDetachingProducts(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if err := repo.store.checkCategoryDelete(id, false); err != nil {
+		return err
+	}
+
+	for productID, product := range repo.store.products {
+		if product.CategoryID != nil && *product.CategoryID == id {
+			product.CategoryID = nil
+			repo.store.products[productID] = product
+		}
+	}
+	delete(repo.store.categories, id)
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"store-api-go/internal/models"
+	"time"
+)
+
+// idempotencyID mirrors the idempotency_keys primary key
+type idempotencyID struct {
+	key      string
+	username string
+}
+
+type IdempotencyRepo struct {
+	store *Store
+}
+
+func NewIdempotencyRepo(store *Store) *IdempotencyRepo {
+	return &IdempotencyRepo{store: store}
+}
+
+func (repo *IdempotencyRepo) Reserve(ctx context.Context, record *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	now := repo.store.now()
+	id := idempotencyID{key: record.Key, username: record.Username}
+	if existing, exists := repo.store.idempotencyKeys[id]; exists && existing.ExpiresAt.After(now) {
+		existing.Body = append([]byte(nil), existing.Body...)
+		return &existing, nil
+	}
+
+	record.StatusCode = 0
+	record.ContentType = ""
+	record.Body = nil
+	record.CreatedAt = now
+	repo.store.idempotencyKeys[id] = *record
+
+	return nil, nil
+}
+
+func (repo *IdempotencyRepo) Complete(ctx context.Context, record *models.IdempotencyKey) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	id := idempotencyID{key: record.Key, username: record.Username}
+	existing, exists := repo.store.idempotencyKeys[id]
+	if !exists || !owns(existing, record) {
+		return nil
+	}
+	existing.StatusCode = record.StatusCode
+	existing.ContentType = record.ContentType
+	existing.Body = append([]byte(nil), record.Body...)
+	existing.ExpiresAt = record.ExpiresAt
+	repo.store.idempotencyKeys[id] = existing
+
+	return nil
+}
+
+func (repo *IdempotencyRepo) Release(ctx context.Context, record *models.IdempotencyKey) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	id := idempotencyID{key: record.Key, username: record.Username}
+	if existing, exists := repo.store.idempotencyKeys[id]; exists && owns(existing, record) {
+		delete(repo.store.idempotencyKeys, id)
+	}
+
+	return nil
+}
+
+// owns reports whether stored is still the pending reservation record made
+func owns(stored models.IdempotencyKey, record *models.IdempotencyKey) bool {
+	return stored.StatusCode == 0 && stored.CreatedAt.Equal(record.CreatedAt)
+}
+
+func (repo *IdempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	deleted := 0
+	for id, record := range repo.store.idempotencyKeys {
+		if !record.ExpiresAt.After(now) {
+			delete(repo.store.idempotencyKeys, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+)
+
+type LocationRepo struct {
+	store *Store
+}
+
+func NewLocationRepo(store *Store) *LocationRepo {
+	return &LocationRepo{store: store}
+}
+
+func (repo *LocationRepo) GetAll(ctx context.Context) ([]models.Location, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	locations := make([]models.Location, 0, len(repo.store.locations))
+	for _, location := range repo.store.locations {
+		locations = append(locations, location)
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].ID < locations[j].ID })
+
+	return locations, nil
+}
+
+func (repo *LocationRepo) GetByID(ctx context.Context, id int) (*models.Location, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	location, exists := repo.store.locations[id]
+	if !exists {
+		return nil, apperrors.NotFound("location_not_found", "Location not found")
+	}
+
+	return &location, nil
+}
+
+// locationNameTaken mirrors the locations.name unique constraint. Callers must hold the store lock.
+func (s *Store) locationNameTaken(name string, exceptID int) bool {
+	for _, location := range s.locations {
+		if location.Name == name && location.ID != exceptID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (repo *LocationRepo) Create(ctx context.Context, location *models.Location) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if repo.store.locationNameTaken(location.Name, 0) {
+		return apperrors.Conflict("location_name_taken", "Location name already exists")
+	}
+
+	repo.store.lastLocationID++
+	location.ID = repo.store.lastLocationID
+	location.CreatedAt = repo.store.now()
+	repo.store.locations[location.ID] = *location
+
+	return nil
+}
+
+func (repo *LocationRepo) Update(ctx context.Context, location *models.Location) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	existing, exists := repo.store.locations[location.ID]
+	if !exists {
+		return apperrors.NotFound("location_not_found", "Location not found")
+	}
+	if repo.store.locationNameTaken(location.Name, location.ID) {
+		return apperrors.Conflict("location_name_taken", "Location name already exists")
+	}
+
+	location.CreatedAt = existing.CreatedAt
+	repo.store.locations[location.ID] = *location
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"sort"
+	"store-api-go/internal/pagination"
+)
+
+// page sorts rows in the requested order and returns the page after the cursor
+func page[T pagination.Keyed](rows []T, order []pagination.SortField, cursor string, limit int) ([]T, string, error) {
+	sort.Slice(rows, func(i, j int) bool {
+		return pagination.Compare(order, pagination.Key(rows[i], order), pagination.Key(rows[j], order)) < 0
+	})
+
+	if cursor != "" {
+		after, err := pagination.DecodeCursor(order, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+
+		start := sort.Search(len(rows), func(i int) bool {
+			return pagination.Compare(order, pagination.Key(rows[i], order), after) > 0
+		})
+		rows = rows[start:]
+	}
+
+	if len(rows) > limit+1 {
+		rows = rows[:limit+1]
+	}
+	rows, next := pagination.Trim(rows, order, limit)
+
+	return rows, next, nil
+}
@@ -0,0 +1,243 @@
+package memory
+
+import (
+	"context"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+	"strings"
+)
+
+type ProductRepo struct {
+	store *Store
+}
+
+func NewProductRepo(store *Store) *ProductRepo {
+	return &ProductRepo{store: store}
+}
+
+// withCategory returns a copy of the product with the category name joined in.
+// Callers must hold the store lock.
+func (s *Store) withCategory(product models.Product) models.Product {
+	product.CategoryID = cloneIntPtr(product.CategoryID)
+	product.TaxClassID = cloneIntPtr(product.TaxClassID)
+	product.CategoryName = ""
+	if product.CategoryID != nil {
+		product.CategoryName = s.categories[*product.CategoryID].Name
+	}
+
+	return product
+}
+
+// checkProductRefs mirrors the products foreign keys. Callers must hold the store lock.
+func (s *Store) checkProductRefs(product *models.Product) error {
+	if product.CategoryID != nil {
+		if _, exists := s.categories[*product.CategoryID]; !exists {
+			return apperrors.NotFound("category_not_found", "Category not found")
+		}
+	}
+	if product.TaxClassID != nil {
+		if _, exists := s.taxClasses[*product.TaxClassID]; !exists {
+			return apperrors.NotFound("tax_class_not_found", "Tax class not found")
+		}
+	}
+
+	return nil
+}
+
+// productHasSales mirrors the transaction_details.product_id foreign key.
+// Callers must hold the store lock.
+func (s *Store) productHasSales(productID int) bool {
+	for _, detail := range s.transactionDetails {
+		if detail.ProductID == productID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (repo *ProductRepo) GetAll(ctx context.Context, filter models.ProductFilter) ([]models.Product, string, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	products := make([]models.Product, 0, len(repo.store.products))
+	for _, product := range repo.store.products {
+		if filter.Name != "" && !strings.Contains(strings.ToLower(product.Name), strings.ToLower(filter.Name)) {
+			continue
+		}
+		if filter.CategoryID != 0 && (product.CategoryID == nil || *product.CategoryID != filter.CategoryID) {
+			continue
+		}
+		if filter.MinPrice != 0 && product.Price < filter.MinPrice {
+			continue
+		}
+		if filter.MaxPrice != 0 && product.Price > filter.MaxPrice {
+			continue
+		}
+		if filter.LocationID != 0 {
+			product.Stock = repo.store.productStocks[product.ID][filter.LocationID]
+		}
+		if filter.InStock != nil && (product.Stock > 0) != *filter.InStock {
+			continue
+		}
+		product = repo.store.withCategory(product)
+		if filter.StockByLocation {
+			product.Stocks = repo.store.stocksOf(product.ID)
+		}
+		products = append(products, product)
+	}
+
+	return page(products, filter.Sort, filter.Cursor, filter.Limit)
+}
+
+// Create puts the product's opening stock at locationID
+func (repo *ProductRepo) Create(ctx context.Context, product *models.Product, locationID int, actor string) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if err := repo.store.checkProductRefs(product); err != nil {
+		return err
+	}
+	// same guarantee as the unique index on LOWER(products.name)
+	if repo.store.productNameTaken(product.Name, 0) {
+		return repositories.NameTaken()
+	}
+
+	if product.Stock != 0 {
+		if _, exists := repo.store.locations[locationID]; !exists {
+			return apperrors.NotFound("location_not_found", "Location not found")
+		}
+	}
+
+	repo.store.lastProductID++
+	product.ID = repo.store.lastProductID
+	stored := repo.store.withCategory(*product)
+	stored.Stock = 0
+	stored.Stocks = nil
+	repo.store.products[product.ID] = stored
+
+	if product.Stock != 0 {
+		repo.store.changeStock(&models.StockMovement{
+			ProductID:     product.ID,
+			LocationID:    locationID,
+			Reason:        models.StockReasonAdjustment,
+			QuantityDelta: product.Stock,
+			Actor:         actor,
+			Note:          "opening balance",
+		})
+	}
+
+	return nil
+}
+
+func (repo *ProductRepo) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	product, exists := repo.store.products[id]
+	if !exists {
+		return nil, apperrors.NotFound("product_not_found", "Product not found")
+	}
+	product = repo.store.withCategory(product)
+	product.Stocks = repo.store.stocksOf(id)
+
+	return &product, nil
+}
+
+func (repo *ProductRepo) Update(ctx context.Context, product *models.Product) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	existing, exists := repo.store.products[product.ID]
+	if !exists {
+		return apperrors.NotFound("product_not_found", "Product not found")
+	}
+	if err := repo.store.checkProductRefs(product); err != nil {
+		return err
+	}
+	if repo.store.productNameTaken(product.Name, product.ID) {
+		return repositories.NameTaken()
+	}
+
+	// stock is owned by the ledger
+	updated := *product
+	updated.Stock = existing.Stock
+	updated.Stocks = nil
+	repo.store.products[product.ID] = repo.store.withCategory(updated)
+
+	return nil
+}
+
+func (repo *ProductRepo) Delete(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, exists := repo.store.products[id]; !exists {
+		return apperrors.NotFound("product_not_found", "Product not found")
+	}
+	if repo.store.productHasSales(id) {
+		return apperrors.Conflict("product_in_use", "Product is referenced by transactions")
+	}
+	for _, transfer := range repo.store.transfers {
+		for _, item := range transfer.Items {
+			if item.ProductID == id {
+				return apperrors.Conflict("product_in_use", "Product is referenced by transfers")
+			}
+		}
+	}
+	for _, order := range repo.store.purchaseOrders {
+		for _, line := range order.Lines {
+			if line.ProductID == id {
+				return apperrors.Conflict("product_in_use", "Product is referenced by purchase orders")
+			}
+		}
+	}
+	repo.store.deleteProduct(id)
+
+	return nil
+}
+
+// deleteProduct removes the product along with the rows that cascade with it.
+// Callers must hold the store lock.
+func (s *Store) deleteProduct(id int) {
+	delete(s.products, id)
+	delete(s.productStocks, id)
+	s.deleteMovements(id)
+	for promotionID, promotion := range s.promotions {
+		if promotion.ProductID != nil && *promotion.ProductID == id {
+			s.deletePromotion(promotionID)
+		}
+	}
+}
+
+// deleteMovements mirrors the ON DELETE CASCADE on stock_movements.product_id.
+// Callers must hold the store lock.
+func (s *Store) deleteMovements(productID int) {
+	kept := s.stockMovements[:0]
+	for _, movement := range s.stockMovements {
+		if movement.ProductID != productID {
+			kept = append(kept, movement)
+		}
+	}
+	s.stockMovements = kept
+}
+
+func (repo *ProductRepo) NameExists(ctx context.Context, name string, excludeID int) (bool, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	return repo.store.productNameTaken(name, excludeID), nil
+}
+
+// productNameTaken reports whether a product other than excludeID has the name,
+// ignoring case. Callers must hold the store lock.
+func (s *Store) productNameTaken(name string, excludeID int) bool {
+	for _, product := range s.products {
+		if product.ID != excludeID && strings.EqualFold(product.Name, name) {
+			return true
+		}
+	}
+
+	return false
+}
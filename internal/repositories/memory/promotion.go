@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"time"
+)
+
+type PromotionRepo struct {
+	store *Store
+}
+
+func NewPromotionRepo(store *Store) *PromotionRepo {
+	return &PromotionRepo{store: store}
+}
+
+// clonePromotion copies the promotion so callers can't modify what the store holds
+func clonePromotion(promotion models.Promotion) models.Promotion {
+	promotion.ProductID = cloneIntPtr(promotion.ProductID)
+	promotion.CategoryID = cloneIntPtr(promotion.CategoryID)
+	if promotion.StartsAt != nil {
+		startsAt := *promotion.StartsAt
+		promotion.StartsAt = &startsAt
+	}
+	if promotion.EndsAt != nil {
+		endsAt := *promotion.EndsAt
+		promotion.EndsAt = &endsAt
+	}
+	if promotion.Active != nil {
+		active := *promotion.Active
+		promotion.Active = &active
+	}
+
+	return promotion
+}
+
+func (repo *PromotionRepo) list(keep func(models.Promotion) bool) []models.Promotion {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	promotions := make([]models.Promotion, 0)
+	for _, promotion := range repo.store.promotions {
+		if keep(promotion) {
+			promotions = append(promotions, clonePromotion(promotion))
+		}
+	}
+	sort.Slice(promotions, func(i, j int) bool { return promotions[i].ID < promotions[j].ID })
+
+	return promotions
+}
+
+func (repo *PromotionRepo) GetAll(ctx context.Context) ([]models.Promotion, error) {
+	return repo.list(func(models.Promotion) bool { return true }), nil
+}
+
+func (repo *PromotionRepo) GetRunning(ctx context.Context, now time.Time) ([]models.Promotion, error) {
+	return repo.list(func(promotion models.Promotion) bool { return promotion.RunsAt(now) }), nil
+}
+
+func (repo *PromotionRepo) GetByID(ctx context.Context, id int) (*models.Promotion, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	promotion, exists := repo.store.promotions[id]
+	if !exists {
+		return nil, apperrors.NotFound("promotion_not_found", "Promotion not found")
+	}
+	promotion = clonePromotion(promotion)
+
+	return &promotion, nil
+}
+
+// checkPromotion mirrors the promotions foreign keys and the coupon_code unique constraint.
+// Callers must hold the store lock.
+func (s *Store) checkPromotion(promotion *models.Promotion) error {
+	if promotion.ProductID != nil {
+		if _, exists := s.products[*promotion.ProductID]; !exists {
+			return apperrors.NotFound("product_not_found", "Product not found")
+		}
+	}
+	if promotion.CategoryID != nil {
+		if _, exists := s.categories[*promotion.CategoryID]; !exists {
+			return apperrors.NotFound("category_not_found", "Category not found")
+		}
+	}
+
+	if promotion.CouponCode != "" {
+		for _, existing := range s.promotions {
+			if existing.CouponCode == promotion.CouponCode && existing.ID != promotion.ID {
+				return apperrors.Conflict("coupon_code_taken", "Coupon code already exists")
+			}
+		}
+	}
+
+	return nil
+}
+
+func (repo *PromotionRepo) Create(ctx context.Context, promotion *models.Promotion) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if err := repo.store.checkPromotion(promotion); err != nil {
+		return err
+	}
+
+	repo.store.lastPromotionID++
+	promotion.ID = repo.store.lastPromotionID
+	promotion.CreatedAt = repo.store.now()
+	repo.store.promotions[promotion.ID] = clonePromotion(*promotion)
+
+	return nil
+}
+
+func (repo *PromotionRepo) Update(ctx context.Context, promotion *models.Promotion) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	existing, exists := repo.store.promotions[promotion.ID]
+	if !exists {
+		return apperrors.NotFound("promotion_not_found", "Promotion not found")
+	}
+	if err := repo.store.checkPromotion(promotion); err != nil {
+		return err
+	}
+
+	promotion.CreatedAt = existing.CreatedAt
+	repo.store.promotions[promotion.ID] = clonePromotion(*promotion)
+
+	return nil
+}
+
+func (repo *PromotionRepo) Delete(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, exists := repo.store.promotions[id]; !exists {
+		return apperrors.NotFound("promotion_not_found", "Promotion not found")
+	}
+	repo.store.deletePromotion(id)
+
+	return nil
+}
+
+// deletePromotion mirrors the ON DELETE SET NULL on transaction_details.promotion_id.
+// Callers must hold the store lock.
+func (s *Store) deletePromotion(id int) {
+	delete(s.promotions, id)
+	for i := range s.transactionDetails {
+		if promotionID := s.transactionDetails[i].PromotionID; promotionID != nil && *promotionID == id {
+			s.transactionDetails[i].PromotionID = nil
+		}
+	}
+}
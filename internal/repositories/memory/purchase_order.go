@@ -0,0 +1,252 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+)
+
+type PurchaseOrderRepo struct {
+	store *Store
+}
+
+func NewPurchaseOrderRepo(store *Store) *PurchaseOrderRepo {
+	return &PurchaseOrderRepo{store: store}
+}
+
+// purchaseOrderOf returns a copy of the order with supplier and product names, total cost
+// and, when withDetails is set, its lines and receipts. Callers must hold the store lock.
+func (s *Store) purchaseOrderOf(order models.PurchaseOrder, withDetails bool) models.PurchaseOrder {
+	order.SupplierName = s.suppliers[order.SupplierID].Name
+	if order.SentAt != nil {
+		sentAt := *order.SentAt
+		order.SentAt = &sentAt
+	}
+	if order.ClosedAt != nil {
+		closedAt := *order.ClosedAt
+		order.ClosedAt = &closedAt
+	}
+
+	lines := append([]models.PurchaseOrderLine(nil), order.Lines...)
+	order.TotalCost = 0
+	for i := range lines {
+		lines[i].ProductName = s.products[lines[i].ProductID].Name
+		order.TotalCost += lines[i].QuantityOrdered * lines[i].UnitCost
+	}
+
+	order.Lines, order.Receipts = nil, nil
+	if withDetails {
+		order.Lines = lines
+		order.Receipts = make([]models.GoodsReceipt, 0)
+		for _, receipt := range s.goodsReceipts {
+			if receipt.PurchaseOrderID == order.ID {
+				receipt.Items = append([]models.GoodsReceiptItem(nil), receipt.Items...)
+				order.Receipts = append(order.Receipts, receipt)
+			}
+		}
+	}
+
+	return order
+}
+
+func (repo *PurchaseOrderRepo) GetAll(ctx context.Context, filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, int, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	orders := make([]models.PurchaseOrder, 0)
+	for _, order := range repo.store.purchaseOrders {
+		if filter.Status != "" && order.Status != filter.Status {
+			continue
+		}
+		if filter.SupplierID != 0 && order.SupplierID != filter.SupplierID {
+			continue
+		}
+		orders = append(orders, repo.store.purchaseOrderOf(order, false))
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID > orders[j].ID })
+
+	total := len(orders)
+	start := min((filter.Page-1)*filter.Limit, total)
+	end := min(start+filter.Limit, total)
+
+	return orders[start:end], total, nil
+}
+
+func (repo *PurchaseOrderRepo) GetByID(ctx context.Context, id int) (*models.PurchaseOrder, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	order, exists := repo.store.purchaseOrders[id]
+	if !exists {
+		return nil, apperrors.NotFound("purchase_order_not_found", "Purchase order not found")
+	}
+	order = repo.store.purchaseOrderOf(order, true)
+
+	return &order, nil
+}
+
+func (repo *PurchaseOrderRepo) Create(ctx context.Context, order *models.PurchaseOrder) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, exists := repo.store.suppliers[order.SupplierID]; !exists {
+		return apperrors.NotFound("supplier_not_found", "Supplier not found")
+	}
+	if _, exists := repo.store.locations[order.LocationID]; !exists {
+		return apperrors.NotFound("location_not_found", "Location not found")
+	}
+	for _, line := range order.Lines {
+		if _, exists := repo.store.products[line.ProductID]; !exists {
+			return apperrors.NotFound("product_not_found", "product id %d not found", line.ProductID)
+		}
+	}
+
+	repo.store.lastPurchaseOrderID++
+	order.ID = repo.store.lastPurchaseOrderID
+	order.Status = models.PurchaseOrderDraft
+	order.CreatedAt = repo.store.now()
+	order.SentAt, order.ClosedAt = nil, nil
+
+	for i := range order.Lines {
+		repo.store.lastPurchaseOrderLineID++
+		order.Lines[i].ID = repo.store.lastPurchaseOrderLineID
+		order.Lines[i].PurchaseOrderID = order.ID
+		order.Lines[i].QuantityReceived = 0
+	}
+
+	stored := *order
+	stored.Lines = append([]models.PurchaseOrderLine(nil), order.Lines...)
+	repo.store.purchaseOrders[order.ID] = stored
+
+	return nil
+}
+
+// saveStatus stores the order with its new status, stamping when it was sent or closed.
+// Callers must hold the store lock.
+func (s *Store) saveStatus(order models.PurchaseOrder) {
+	now := s.now()
+	switch order.Status {
+	case models.PurchaseOrderSent:
+		order.SentAt = &now
+	case models.PurchaseOrderClosed:
+		order.ClosedAt = &now
+	}
+
+	order.Lines = append([]models.PurchaseOrderLine(nil), order.Lines...)
+	order.Receipts = nil
+	s.purchaseOrders[order.ID] = order
+}
+
+func (repo *PurchaseOrderRepo) UpdateStatus(ctx context.Context, id int, transition repositories.PurchaseOrderFunc) (*models.PurchaseOrder, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	stored, exists := repo.store.purchaseOrders[id]
+	if !exists {
+		return nil, apperrors.NotFound("purchase_order_not_found", "Purchase order not found")
+	}
+
+	order := repo.store.purchaseOrderOf(stored, true)
+	if err := transition(&order); err != nil {
+		return nil, err
+	}
+
+	stored.Status = order.Status
+	repo.store.saveStatus(stored)
+
+	order = repo.store.purchaseOrderOf(repo.store.purchaseOrders[id], true)
+	return &order, nil
+}
+
+// Receive plans the receipt on a copy of the order, so a rejected delivery changes nothing
+func (repo *PurchaseOrderRepo) Receive(ctx context.Context, id int, receipt *models.GoodsReceipt, plan repositories.ReceiptFunc) (*models.PurchaseOrder, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	stored, exists := repo.store.purchaseOrders[id]
+	if !exists {
+		return nil, apperrors.NotFound("purchase_order_not_found", "Purchase order not found")
+	}
+
+	order := repo.store.purchaseOrderOf(stored, true)
+	receipt.PurchaseOrderID = id
+	if err := plan(&order, receipt); err != nil {
+		return nil, err
+	}
+
+	repo.store.lastGoodsReceiptID++
+	receipt.ID = repo.store.lastGoodsReceiptID
+	receipt.CreatedAt = repo.store.now()
+
+	lineIndex := make(map[int]int, len(stored.Lines))
+	for i, line := range stored.Lines {
+		lineIndex[line.ID] = i
+	}
+	stored.Lines = append([]models.PurchaseOrderLine(nil), stored.Lines...)
+
+	for i := range receipt.Items {
+		item := &receipt.Items[i]
+		repo.store.lastGoodsReceiptItemID++
+		item.ID = repo.store.lastGoodsReceiptItemID
+		item.ReceiptID = receipt.ID
+
+		stored.Lines[lineIndex[item.LineID]].QuantityReceived += item.Quantity
+
+		repo.store.changeStock(&models.StockMovement{
+			ProductID:     item.ProductID,
+			LocationID:    stored.LocationID,
+			Reason:        models.StockReasonReceipt,
+			QuantityDelta: item.Quantity,
+			Actor:         receipt.ReceivedBy,
+			ReferenceID:   &receipt.ID,
+			Note:          fmt.Sprintf("purchase order %d", id),
+		})
+	}
+
+	storedReceipt := *receipt
+	storedReceipt.Items = append([]models.GoodsReceiptItem(nil), receipt.Items...)
+	repo.store.goodsReceipts = append(repo.store.goodsReceipts, storedReceipt)
+
+	stored.Status = order.Status
+	repo.store.saveStatus(stored)
+
+	order = repo.store.purchaseOrderOf(repo.store.purchaseOrders[id], true)
+	return &order, nil
+}
+
+func (repo *PurchaseOrderRepo) OnOrder(ctx context.Context) ([]models.OnOrderProduct, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	outstanding := make(map[int]int)
+	for _, order := range repo.store.purchaseOrders {
+		if order.Status != models.PurchaseOrderSent && order.Status != models.PurchaseOrderPartiallyReceived {
+			continue
+		}
+		for _, line := range order.Lines {
+			if line.QuantityReceived < line.QuantityOrdered {
+				outstanding[line.ProductID] += line.QuantityOrdered - line.QuantityReceived
+			}
+		}
+	}
+
+	products := make([]models.OnOrderProduct, 0, len(outstanding))
+	for productID, quantity := range outstanding {
+		products = append(products, models.OnOrderProduct{
+			ProductID:   productID,
+			ProductName: repo.store.products[productID].Name,
+			Quantity:    quantity,
+		})
+	}
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].ProductName != products[j].ProductName {
+			return products[i].ProductName < products[j].ProductName
+		}
+		return products[i].ProductID < products[j].ProductID
+	})
+
+	return products, nil
+}
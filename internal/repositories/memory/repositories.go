@@ -0,0 +1,17 @@
+package memory
+
+import "store-api-go/internal/repositories"
+
+var (
+	_ repositories.UserRepository          = (*UserRepo)(nil)
+	_ repositories.CategoryRepository      = (*CategoryRepo)(nil)
+	_ repositories.ProductRepository       = (*ProductRepo)(nil)
+	_ repositories.TransactionRepository   = (*TransactionRepo)(nil)
+	_ repositories.LocationRepository      = (*LocationRepo)(nil)
+	_ repositories.TransferRepository      = (*TransferRepo)(nil)
+	_ repositories.SupplierRepository      = (*SupplierRepo)(nil)
+	_ repositories.PurchaseOrderRepository = (*PurchaseOrderRepo)(nil)
+	_ repositories.PromotionRepository     = (*PromotionRepo)(nil)
+	_ repositories.TaxClassRepository      = (*TaxClassRepo)(nil)
+	_ repositories.IdempotencyRepository   = (*IdempotencyRepo)(nil)
+)
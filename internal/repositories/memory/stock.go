@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+)
+
+// recordMovement appends a stock ledger entry. Callers must hold the store lock.
+func (s *Store) recordMovement(movement *models.StockMovement) {
+	s.lastStockMovementID++
+	movement.ID = s.lastStockMovementID
+	movement.CreatedAt = s.now()
+	movement.ReferenceID = cloneIntPtr(movement.ReferenceID)
+
+	s.stockMovements = append(s.stockMovements, *movement)
+}
+
+// changeStock applies the movement to the product's stock at its location and to the
+// product total, then records it. Callers must hold the store lock and have checked
+// the location has enough stock.
+func (s *Store) changeStock(movement *models.StockMovement) {
+	product := s.products[movement.ProductID]
+	product.Stock += movement.QuantityDelta
+	s.products[movement.ProductID] = product
+
+	stocks, exists := s.productStocks[movement.ProductID]
+	if !exists {
+		stocks = make(map[int]int)
+		s.productStocks[movement.ProductID] = stocks
+	}
+	stocks[movement.LocationID] += movement.QuantityDelta
+
+	movement.BalanceAfter = stocks[movement.LocationID]
+	s.recordMovement(movement)
+}
+
+// stocksOf returns the product's per location stock. Callers must hold the store lock.
+func (s *Store) stocksOf(productID int) []models.ProductStock {
+	stocks := s.productStocks[productID]
+	locationIDs := make([]int, 0, len(stocks))
+	for locationID := range stocks {
+		locationIDs = append(locationIDs, locationID)
+	}
+	sort.Ints(locationIDs)
+
+	var result []models.ProductStock
+	for _, locationID := range locationIDs {
+		result = append(result, models.ProductStock{
+			LocationID:   locationID,
+			LocationName: s.locations[locationID].Name,
+			Quantity:     stocks[locationID],
+		})
+	}
+
+	return result
+}
+
+func (repo *ProductRepo) AdjustStock(ctx context.Context, movement *models.StockMovement, adjust repositories.StockFunc) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, exists := repo.store.products[movement.ProductID]; !exists {
+		return apperrors.NotFound("product_not_found", "Product not found")
+	}
+
+	current := repo.store.productStocks[movement.ProductID][movement.LocationID]
+	delta, err := adjust(current)
+	if err != nil {
+		return err
+	}
+	if current+delta < 0 {
+		return apperrors.InsufficientStock("out_of_stock", "product id %d is out of stock", movement.ProductID)
+	}
+
+	movement.QuantityDelta = delta
+	repo.store.changeStock(movement)
+
+	return nil
+}
+
+func (repo *ProductRepo) GetStockHistory(ctx context.Context, productID int, page int, limit int) ([]models.StockMovement, int, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	// newest first
+	movements := make([]models.StockMovement, 0)
+	for i := len(repo.store.stockMovements) - 1; i >= 0; i-- {
+		if repo.store.stockMovements[i].ProductID == productID {
+			movement := repo.store.stockMovements[i]
+			movement.ReferenceID = cloneIntPtr(movement.ReferenceID)
+			movements = append(movements, movement)
+		}
+	}
+
+	total := len(movements)
+	start := min((page-1)*limit, total)
+	end := min(start+limit, total)
+
+	return movements[start:end], total, nil
+}
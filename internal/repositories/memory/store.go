@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"store-api-go/internal/models"
+	"sync"
+	"time"
+)
+
+// Store holds every table in memory behind a single lock, so multi row operations
+// such as checkout are applied all at once or not at all.
+type Store struct {
+	mu sync.RWMutex
+
+	users              map[int]models.User
+	categories         map[int]models.Category
+	products           map[int]models.Product
+	transactions       map[int]models.Transaction
+	transactionDetails []models.TransactionDetail
+	payments           []models.Payment
+	refunds            []models.Refund
+	stockMovements     []models.StockMovement
+	locations          map[int]models.Location
+	productStocks      map[int]map[int]int // product id -> location id -> quantity
+	transfers          map[int]models.StockTransfer
+	suppliers          map[int]models.Supplier
+	purchaseOrders     map[int]models.PurchaseOrder
+	goodsReceipts      []models.GoodsReceipt
+	promotions         map[int]models.Promotion
+	taxClasses         map[int]models.TaxClass
+	idempotencyKeys    map[idempotencyID]models.IdempotencyKey
+
+	lastUserID              int
+	lastCategoryID          int
+	lastProductID           int
+	lastTransactionID       int
+	lastTransactionDetailID int
+	lastPaymentID           int
+	lastRefundID            int
+	lastRefundItemID        int
+	lastStockMovementID     int
+	lastLocationID          int
+	lastTransferID          int
+	lastTransferItemID      int
+	lastSupplierID          int
+	lastPurchaseOrderID     int
+	lastPurchaseOrderLineID int
+	lastGoodsReceiptID      int
+	lastGoodsReceiptItemID  int
+	lastPromotionID         int
+	lastTaxClassID          int
+
+	now func() time.Time
+}
+
+// NewStore starts with one store location, like the locations migration
+func NewStore() *Store {
+	store := &Store{
+		users:           make(map[int]models.User),
+		categories:      make(map[int]models.Category),
+		products:        make(map[int]models.Product),
+		transactions:    make(map[int]models.Transaction),
+		locations:       make(map[int]models.Location),
+		productStocks:   make(map[int]map[int]int),
+		transfers:       make(map[int]models.StockTransfer),
+		suppliers:       make(map[int]models.Supplier),
+		purchaseOrders:  make(map[int]models.PurchaseOrder),
+		promotions:      make(map[int]models.Promotion),
+		taxClasses:      make(map[int]models.TaxClass),
+		idempotencyKeys: make(map[idempotencyID]models.IdempotencyKey),
+		now:             time.Now,
+	}
+
+	store.lastLocationID++
+	store.locations[store.lastLocationID] = models.Location{
+		ID:        store.lastLocationID,
+		Name:      "Main Store",
+		Type:      models.LocationTypeStore,
+		CreatedAt: store.now(),
+	}
+
+	return store
+}
+
+func cloneIntPtr(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	clone := *value
+	return &clone
+}
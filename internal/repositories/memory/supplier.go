@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+)
+
+type SupplierRepo struct {
+	store *Store
+}
+
+func NewSupplierRepo(store *Store) *SupplierRepo {
+	return &SupplierRepo{store: store}
+}
+
+func (repo *SupplierRepo) GetAll(ctx context.Context) ([]models.Supplier, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	suppliers := make([]models.Supplier, 0, len(repo.store.suppliers))
+	for _, supplier := range repo.store.suppliers {
+		suppliers = append(suppliers, supplier)
+	}
+	sort.Slice(suppliers, func(i, j int) bool {
+		if suppliers[i].Name != suppliers[j].Name {
+			return suppliers[i].Name < suppliers[j].Name
+		}
+		return suppliers[i].ID < suppliers[j].ID
+	})
+
+	return suppliers, nil
+}
+
+func (repo *SupplierRepo) GetByID(ctx context.Context, id int) (*models.Supplier, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	supplier, exists := repo.store.suppliers[id]
+	if !exists {
+		return nil, apperrors.NotFound("supplier_not_found", "Supplier not found")
+	}
+
+	return &supplier, nil
+}
+
+// supplierNameTaken mirrors the suppliers.name unique constraint. Callers must hold the store lock.
+func (s *Store) supplierNameTaken(name string, exceptID int) bool {
+	for _, supplier := range s.suppliers {
+		if supplier.Name == name && supplier.ID != exceptID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (repo *SupplierRepo) Create(ctx context.Context, supplier *models.Supplier) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if repo.store.supplierNameTaken(supplier.Name, 0) {
+		return apperrors.Conflict("supplier_name_taken", "Supplier name already exists")
+	}
+
+	repo.store.lastSupplierID++
+	supplier.ID = repo.store.lastSupplierID
+	supplier.CreatedAt = repo.store.now()
+	repo.store.suppliers[supplier.ID] = *supplier
+
+	return nil
+}
+
+func (repo *SupplierRepo) Update(ctx context.Context, supplier *models.Supplier) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	existing, exists := repo.store.suppliers[supplier.ID]
+	if !exists {
+		return apperrors.NotFound("supplier_not_found", "Supplier not found")
+	}
+	if repo.store.supplierNameTaken(supplier.Name, supplier.ID) {
+		return apperrors.Conflict("supplier_name_taken", "Supplier name already exists")
+	}
+
+	supplier.CreatedAt = existing.CreatedAt
+	repo.store.suppliers[supplier.ID] = *supplier
+
+	return nil
+}
+
+func (repo *SupplierRepo) Delete(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, exists := repo.store.suppliers[id]; !exists {
+		return apperrors.NotFound("supplier_not_found", "Supplier not found")
+	}
+	for _, order := range repo.store.purchaseOrders {
+		if order.SupplierID == id {
+			return apperrors.Conflict("supplier_in_use", "Supplier has purchase orders")
+		}
+	}
+	delete(repo.store.suppliers, id)
+
+	return nil
+}
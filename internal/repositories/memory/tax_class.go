@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+)
+
+type TaxClassRepo struct {
+	store *Store
+}
+
+func NewTaxClassRepo(store *Store) *TaxClassRepo {
+	return &TaxClassRepo{store: store}
+}
+
+func (repo *TaxClassRepo) GetAll(ctx context.Context) ([]models.TaxClass, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	taxClasses := make([]models.TaxClass, 0, len(repo.store.taxClasses))
+	for _, taxClass := range repo.store.taxClasses {
+		taxClasses = append(taxClasses, taxClass)
+	}
+	sort.Slice(taxClasses, func(i, j int) bool {
+		if taxClasses[i].Name != taxClasses[j].Name {
+			return taxClasses[i].Name < taxClasses[j].Name
+		}
+		return taxClasses[i].ID < taxClasses[j].ID
+	})
+
+	return taxClasses, nil
+}
+
+func (repo *TaxClassRepo) GetByID(ctx context.Context, id int) (*models.TaxClass, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	taxClass, exists := repo.store.taxClasses[id]
+	if !exists {
+		return nil, apperrors.NotFound("tax_class_not_found", "Tax class not found")
+	}
+
+	return &taxClass, nil
+}
+
+// taxClassNameTaken mirrors the tax_classes.name unique constraint. Callers must hold the store lock.
+func (s *Store) taxClassNameTaken(name string, exceptID int) bool {
+	for _, taxClass := range s.taxClasses {
+		if taxClass.Name == name && taxClass.ID != exceptID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (repo *TaxClassRepo) Create(ctx context.Context, taxClass *models.TaxClass) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if repo.store.taxClassNameTaken(taxClass.Name, 0) {
+		return apperrors.Conflict("tax_class_name_taken", "Tax class name already exists")
+	}
+
+	repo.store.lastTaxClassID++
+	taxClass.ID = repo.store.lastTaxClassID
+	repo.store.taxClasses[taxClass.ID] = *taxClass
+
+	return nil
+}
+
+// Update only changes future sales, past lines keep the rate they were sold at
+func (repo *TaxClassRepo) Update(ctx context.Context, taxClass *models.TaxClass) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, exists := repo.store.taxClasses[taxClass.ID]; !exists {
+		return apperrors.NotFound("tax_class_not_found", "Tax class not found")
+	}
+	if repo.store.taxClassNameTaken(taxClass.Name, taxClass.ID) {
+		return apperrors.Conflict("tax_class_name_taken", "Tax class name already exists")
+	}
+	repo.store.taxClasses[taxClass.ID] = *taxClass
+
+	return nil
+}
+
+// Delete mirrors the products and transaction_details foreign keys
+func (repo *TaxClassRepo) Delete(ctx context.Context, id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, exists := repo.store.taxClasses[id]; !exists {
+		return apperrors.NotFound("tax_class_not_found", "Tax class not found")
+	}
+	for _, product := range repo.store.products {
+		if product.TaxClassID != nil && *product.TaxClassID == id {
+			return apperrors.Conflict("tax_class_in_use", "Tax class is used by products or sales")
+		}
+	}
+	for _, detail := range repo.store.transactionDetails {
+		if detail.TaxClassID != nil && *detail.TaxClassID == id {
+			return apperrors.Conflict("tax_class_in_use", "Tax class is used by products or sales")
+		}
+	}
+	delete(repo.store.taxClasses, id)
+
+	return nil
+}
@@ -0,0 +1,432 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+type TransactionRepo struct {
+	store    *Store
+	location *time.Location
+}
+
+// NewTransactionRepo buckets sales and refunds into the days of location
+func NewTransactionRepo(store *Store, location *time.Location) *TransactionRepo {
+	return &TransactionRepo{store: store, location: location}
+}
+
+// day is the date t falls on in the repo's location
+func (repo *TransactionRepo) day(t time.Time) string {
+	return t.In(repo.location).Format(dateLayout)
+}
+
+func (repo *TransactionRepo) GetAll(ctx context.Context, filter models.TransactionFilter) ([]models.Transaction, int, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	soldProduct := make(map[int]bool)
+	if filter.ProductID != 0 {
+		for _, detail := range repo.store.transactionDetails {
+			if detail.ProductID == filter.ProductID {
+				soldProduct[detail.TransactionID] = true
+			}
+		}
+	}
+
+	matched := make([]models.Transaction, 0)
+	for _, transaction := range repo.store.transactions {
+		date := repo.day(transaction.CreatedAt)
+		if filter.StartDate != "" && date < filter.StartDate {
+			continue
+		}
+		if filter.EndDate != "" && date > filter.EndDate {
+			continue
+		}
+		if filter.MinAmount != 0 && transaction.TotalAmount < filter.MinAmount {
+			continue
+		}
+		if filter.MaxAmount != 0 && transaction.TotalAmount > filter.MaxAmount {
+			continue
+		}
+		if filter.ProductID != 0 && !soldProduct[transaction.ID] {
+			continue
+		}
+		matched = append(matched, transaction)
+	}
+
+	// newest first
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	total := len(matched)
+	start := min((filter.Page-1)*filter.Limit, total)
+	end := min(start+filter.Limit, total)
+
+	return matched[start:end], total, nil
+}
+
+func (repo *TransactionRepo) GetByID(ctx context.Context, id int) (*models.Transaction, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	transaction, exists := repo.store.transactions[id]
+	if !exists {
+		return nil, apperrors.NotFound("transaction_not_found", "Transaction not found")
+	}
+
+	transaction.Details = repo.store.detailsOf(id)
+	transaction.Payments = make([]models.Payment, 0)
+	for _, payment := range repo.store.payments {
+		if payment.TransactionID == id {
+			transaction.Payments = append(transaction.Payments, payment)
+		}
+	}
+
+	return &transaction, nil
+}
+
+// CreateTransaction validates every line before changing anything, all under the store
+// lock, so a failed checkout leaves stock untouched and concurrent checkouts serialize.
+func (repo *TransactionRepo) CreateTransaction(ctx context.Context, request models.CheckoutRequest, actor string, settle repositories.SettleFunc) (*models.Transaction, error) {
+	items := request.Items
+
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	// Sum quantities per product so repeated lines are checked against the stock together
+	requested := make(map[int]int)
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		if _, exists := requested[item.ProductID]; !exists {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+	if len(productIDs) == 0 {
+		return nil, apperrors.Validation("empty_checkout", "checkout has no items")
+	}
+	sort.Ints(productIDs)
+
+	for _, productID := range productIDs {
+		product, exists := repo.store.products[productID]
+		if !exists {
+			return nil, apperrors.NotFound("product_not_found", "product id %d not found", productID)
+		}
+
+		if repo.store.productStocks[productID][request.LocationID] < requested[productID] {
+			return nil, apperrors.InsufficientStock("out_of_stock", "product id %d is out of stock", product.ID)
+		}
+	}
+
+	transaction := models.Transaction{
+		LocationID: request.LocationID,
+		Details:    make([]models.TransactionDetail, 0, len(items)),
+		Payments:   append([]models.Payment(nil), request.Payments...),
+	}
+
+	for _, item := range items {
+		product := repo.store.products[item.ProductID]
+
+		subtotal := product.Price * item.Quantity
+		transaction.GrossAmount += subtotal
+		transaction.TotalAmount += subtotal
+
+		transaction.Details = append(transaction.Details, models.TransactionDetail{
+			ProductID:   item.ProductID,
+			ProductName: product.Name,
+			Quantity:    item.Quantity,
+			UnitPrice:   product.Price,
+			GrossAmount: subtotal,
+			TaxClassID:  cloneIntPtr(product.TaxClassID),
+			TaxRate:     repo.store.taxRateOf(product),
+			Subtotal:    subtotal,
+			CategoryID:  cloneIntPtr(product.CategoryID),
+		})
+	}
+
+	// settle applies the promotions and tax and takes the payments. Nothing has been written
+	// yet, so a failed settlement leaves the store untouched
+	if err := settle(&transaction); err != nil {
+		return nil, err
+	}
+
+	repo.store.lastTransactionID++
+	transaction.ID = repo.store.lastTransactionID
+	transaction.CreatedAt = repo.store.now()
+
+	for _, productID := range productIDs {
+		repo.store.changeStock(&models.StockMovement{
+			ProductID:     productID,
+			LocationID:    request.LocationID,
+			Reason:        models.StockReasonSale,
+			QuantityDelta: -requested[productID],
+			Actor:         actor,
+			ReferenceID:   &transaction.ID,
+		})
+	}
+
+	for i := range transaction.Details {
+		repo.store.lastTransactionDetailID++
+		transaction.Details[i].ID = repo.store.lastTransactionDetailID
+		transaction.Details[i].TransactionID = transaction.ID
+
+		stored := transaction.Details[i]
+		stored.PromotionID = cloneIntPtr(stored.PromotionID)
+		stored.TaxClassID = cloneIntPtr(stored.TaxClassID)
+		stored.CategoryID = nil
+		repo.store.transactionDetails = append(repo.store.transactionDetails, stored)
+	}
+
+	for i := range transaction.Payments {
+		repo.store.lastPaymentID++
+		transaction.Payments[i].ID = repo.store.lastPaymentID
+		transaction.Payments[i].TransactionID = transaction.ID
+	}
+	repo.store.payments = append(repo.store.payments, transaction.Payments...)
+
+	stored := transaction
+	stored.Details = nil
+	stored.Payments = nil
+	repo.store.transactions[transaction.ID] = stored
+
+	return &transaction, nil
+}
+
+func (repo *TransactionRepo) CreateRefund(ctx context.Context, transactionID int, request models.RefundRequest, actor string, plan repositories.RefundFunc) (*models.Refund, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	stored, exists := repo.store.transactions[transactionID]
+	if !exists {
+		return nil, apperrors.NotFound("transaction_not_found", "Transaction not found")
+	}
+
+	transaction := stored
+	transaction.Details = repo.store.detailsOf(transactionID)
+
+	refund := models.Refund{
+		TransactionID: transactionID,
+		Reason:        request.Reason,
+		Restock:       request.Restock,
+	}
+	if err := plan(&transaction, &refund); err != nil {
+		return nil, err
+	}
+
+	repo.store.lastRefundID++
+	refund.ID = repo.store.lastRefundID
+	refund.CreatedAt = repo.store.now()
+
+	for i := range refund.Items {
+		item := &refund.Items[i]
+		repo.store.lastRefundItemID++
+		item.ID = repo.store.lastRefundItemID
+		item.RefundID = refund.ID
+
+		// restocked items go back to the location they were sold from
+		if refund.Restock {
+			repo.store.changeStock(&models.StockMovement{
+				ProductID:     item.ProductID,
+				LocationID:    transaction.LocationID,
+				Reason:        models.StockReasonRefund,
+				QuantityDelta: item.Quantity,
+				Actor:         actor,
+				ReferenceID:   &refund.ID,
+			})
+		}
+	}
+
+	storedRefund := refund
+	storedRefund.Items = append([]models.RefundItem(nil), refund.Items...)
+	repo.store.refunds = append(repo.store.refunds, storedRefund)
+
+	return &refund, nil
+}
+
+// taxRateOf returns the rate of the product's tax class, settle applies the default
+// rate to products without one. Callers must hold the store lock.
+func (s *Store) taxRateOf(product models.Product) int {
+	if product.TaxClassID == nil {
+		return 0
+	}
+
+	return s.taxClasses[*product.TaxClassID].Rate
+}
+
+// detailsOf returns the transaction's lines with refunded totals and product names filled in.
+// Callers must hold the store lock.
+func (s *Store) detailsOf(transactionID int) []models.TransactionDetail {
+	refundedQty := make(map[int]int)
+	refundedAmount := make(map[int]int)
+	refundedTax := make(map[int]int)
+	for _, refund := range s.refunds {
+		if refund.TransactionID != transactionID {
+			continue
+		}
+		for _, item := range refund.Items {
+			refundedQty[item.TransactionDetailID] += item.Quantity
+			refundedAmount[item.TransactionDetailID] += item.Amount
+			refundedTax[item.TransactionDetailID] += item.TaxAmount
+		}
+	}
+
+	details := make([]models.TransactionDetail, 0)
+	for _, detail := range s.transactionDetails {
+		if detail.TransactionID != transactionID {
+			continue
+		}
+		detail.ProductName = s.products[detail.ProductID].Name
+		detail.PromotionID = cloneIntPtr(detail.PromotionID)
+		detail.TaxClassID = cloneIntPtr(detail.TaxClassID)
+		detail.PromotionName = ""
+		if detail.PromotionID != nil {
+			detail.PromotionName = s.promotions[*detail.PromotionID].Name
+		}
+		detail.RefundedQuantity = refundedQty[detail.ID]
+		detail.RefundedAmount = refundedAmount[detail.ID]
+		detail.RefundedTaxAmount = refundedTax[detail.ID]
+		details = append(details, detail)
+	}
+
+	return details
+}
+
+func (repo *TransactionRepo) Report(ctx context.Context, startDate string, endDate string) (*models.ReportResponse, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	report := models.ReportResponse{}
+
+	totalChange := 0
+	inRange := make(map[int]bool)
+	for _, transaction := range repo.store.transactions {
+		date := repo.day(transaction.CreatedAt)
+		if date < startDate || date > endDate {
+			continue
+		}
+		inRange[transaction.ID] = true
+		report.GrossSales += transaction.GrossAmount
+		report.TotalDiscount += transaction.DiscountAmount
+		report.NetSales += transaction.TotalAmount
+		report.TotalTax += transaction.TaxAmount
+		report.TotalTransaction++
+		totalChange += transaction.ChangeAmount
+	}
+	report.TotalRevenue = report.NetSales
+
+	// Refunds count against the period they were given in
+	for _, refund := range repo.store.refunds {
+		date := repo.day(refund.CreatedAt)
+		if date >= startDate && date <= endDate {
+			report.TotalRefund += refund.Amount
+		}
+	}
+	report.TotalRevenue -= report.TotalRefund
+
+	// Tendered amounts per method, change is handed back from the cash drawer
+	byMethod := make(map[string]int)
+	for _, payment := range repo.store.payments {
+		if inRange[payment.TransactionID] {
+			byMethod[payment.Method] += payment.Amount
+		}
+	}
+
+	report.RevenueByPaymentMethod = make([]models.PaymentMethodRevenue, 0, len(byMethod))
+	for method, amount := range byMethod {
+		if method == models.PaymentMethodCash {
+			amount -= totalChange
+		}
+		report.RevenueByPaymentMethod = append(report.RevenueByPaymentMethod, models.PaymentMethodRevenue{Method: method, Amount: amount})
+	}
+	sort.Slice(report.RevenueByPaymentMethod, func(i, j int) bool {
+		return report.RevenueByPaymentMethod[i].Method < report.RevenueByPaymentMethod[j].Method
+	})
+
+	refundedQty := make(map[int]int)
+	for _, refund := range repo.store.refunds {
+		for _, item := range refund.Items {
+			refundedQty[item.TransactionDetailID] += item.Quantity
+		}
+	}
+
+	soldQty := make(map[int]int)
+	for _, detail := range repo.store.transactionDetails {
+		if inRange[detail.TransactionID] {
+			soldQty[detail.ProductID] += detail.Quantity - refundedQty[detail.ID]
+		}
+	}
+
+	// Best seller is the product with the highest quantity sold in the range and not
+	// refunded since, ties broken by name
+	for productID, qty := range soldQty {
+		if qty <= 0 {
+			continue
+		}
+		name := repo.store.products[productID].Name
+		best := report.BestSellerProduct
+		if qty > best.SoldQty || (qty == best.SoldQty && name < best.Name) {
+			report.BestSellerProduct = models.BestProduct{Name: name, SoldQty: qty}
+		}
+	}
+
+	return &report, nil
+}
+
+// TaxReport groups the tax on sales made in the period and on refunds given in it
+// by tax class and rate
+func (repo *TransactionRepo) TaxReport(ctx context.Context, startDate string, endDate string) (*models.TaxReport, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	report := models.TaxReport{StartDate: startDate, EndDate: endDate, Lines: make([]models.TaxReportLine, 0)}
+
+	details := make(map[int]models.TransactionDetail, len(repo.store.transactionDetails))
+	for _, detail := range repo.store.transactionDetails {
+		details[detail.ID] = detail
+
+		date := repo.day(repo.store.transactions[detail.TransactionID].CreatedAt)
+		if date < startDate || date > endDate {
+			continue
+		}
+		line := repo.store.taxLineOf(detail)
+		line.TaxableAmount = detail.Subtotal - detail.TaxAmount
+		line.TaxAmount = detail.TaxAmount
+		report.Add(line)
+	}
+
+	for _, refund := range repo.store.refunds {
+		date := repo.day(refund.CreatedAt)
+		if date < startDate || date > endDate {
+			continue
+		}
+		for _, item := range refund.Items {
+			line := repo.store.taxLineOf(details[item.TransactionDetailID])
+			line.RefundedTaxableAmount = item.Amount - item.TaxAmount
+			line.RefundedTaxAmount = item.TaxAmount
+			report.Add(line)
+		}
+	}
+
+	return &report, nil
+}
+
+// taxLineOf returns an empty report line for the detail's tax class and rate.
+// Callers must hold the store lock.
+func (s *Store) taxLineOf(detail models.TransactionDetail) models.TaxReportLine {
+	line := models.TaxReportLine{TaxClassID: cloneIntPtr(detail.TaxClassID), Rate: detail.TaxRate}
+	if detail.TaxClassID != nil {
+		line.TaxClassName = s.taxClasses[*detail.TaxClassID].Name
+	}
+
+	return line
+}
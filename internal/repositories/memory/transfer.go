@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+)
+
+type TransferRepo struct {
+	store *Store
+}
+
+func NewTransferRepo(store *Store) *TransferRepo {
+	return &TransferRepo{store: store}
+}
+
+// cloneTransfer copies the transfer so callers can't modify what the store holds
+func cloneTransfer(transfer models.StockTransfer) models.StockTransfer {
+	transfer.Items = append([]models.StockTransferItem(nil), transfer.Items...)
+	if transfer.CompletedAt != nil {
+		completedAt := *transfer.CompletedAt
+		transfer.CompletedAt = &completedAt
+	}
+
+	return transfer
+}
+
+func (repo *TransferRepo) GetAll(ctx context.Context, status string) ([]models.StockTransfer, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	transfers := make([]models.StockTransfer, 0)
+	for _, transfer := range repo.store.transfers {
+		if status != "" && transfer.Status != status {
+			continue
+		}
+		transfer = cloneTransfer(transfer)
+		transfer.Items = nil
+		transfers = append(transfers, transfer)
+	}
+	sort.Slice(transfers, func(i, j int) bool { return transfers[i].ID > transfers[j].ID })
+
+	return transfers, nil
+}
+
+func (repo *TransferRepo) GetByID(ctx context.Context, id int) (*models.StockTransfer, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	transfer, exists := repo.store.transfers[id]
+	if !exists {
+		return nil, apperrors.NotFound("transfer_not_found", "Transfer not found")
+	}
+	transfer = repo.store.withProductNames(cloneTransfer(transfer))
+
+	return &transfer, nil
+}
+
+// withProductNames joins the product names into the transfer's items.
+// Callers must hold the store lock.
+func (s *Store) withProductNames(transfer models.StockTransfer) models.StockTransfer {
+	for i := range transfer.Items {
+		transfer.Items[i].ProductName = s.products[transfer.Items[i].ProductID].Name
+	}
+
+	return transfer
+}
+
+// Create checks every item before moving anything, so a failed transfer leaves stock untouched
+func (repo *TransferRepo) Create(ctx context.Context, transfer *models.StockTransfer) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	for _, item := range transfer.Items {
+		if _, exists := repo.store.products[item.ProductID]; !exists {
+			return apperrors.NotFound("product_not_found", "product id %d not found", item.ProductID)
+		}
+		if repo.store.productStocks[item.ProductID][transfer.FromLocationID] < item.Quantity {
+			return apperrors.InsufficientStock("out_of_stock", "product id %d is out of stock", item.ProductID)
+		}
+	}
+
+	repo.store.lastTransferID++
+	transfer.ID = repo.store.lastTransferID
+	transfer.Status = models.TransferStatusInTransit
+	transfer.CreatedAt = repo.store.now()
+	transfer.CompletedAt = nil
+
+	for i := range transfer.Items {
+		item := &transfer.Items[i]
+		repo.store.lastTransferItemID++
+		item.ID = repo.store.lastTransferItemID
+		item.TransferID = transfer.ID
+
+		repo.store.changeStock(&models.StockMovement{
+			ProductID:     item.ProductID,
+			LocationID:    transfer.FromLocationID,
+			Reason:        models.StockReasonTransferOut,
+			QuantityDelta: -item.Quantity,
+			Actor:         transfer.CreatedBy,
+			ReferenceID:   &transfer.ID,
+		})
+	}
+
+	*transfer = repo.store.withProductNames(*transfer)
+	repo.store.transfers[transfer.ID] = cloneTransfer(*transfer)
+
+	return nil
+}
+
+func (repo *TransferRepo) Complete(ctx context.Context, id int, status string, actor string) (*models.StockTransfer, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	transfer, exists := repo.store.transfers[id]
+	if !exists {
+		return nil, apperrors.NotFound("transfer_not_found", "Transfer not found")
+	}
+	if transfer.Status != models.TransferStatusInTransit {
+		return nil, apperrors.Conflict("transfer_completed", "Transfer is already %s", transfer.Status)
+	}
+
+	// received stock arrives at the destination, cancelled stock goes back to the source
+	locationID, note := transfer.ToLocationID, ""
+	if status == models.TransferStatusCancelled {
+		locationID, note = transfer.FromLocationID, "transfer cancelled"
+	}
+
+	for _, item := range transfer.Items {
+		repo.store.changeStock(&models.StockMovement{
+			ProductID:     item.ProductID,
+			LocationID:    locationID,
+			Reason:        models.StockReasonTransferIn,
+			QuantityDelta: item.Quantity,
+			Actor:         actor,
+			ReferenceID:   &transfer.ID,
+			Note:          note,
+		})
+	}
+
+	completedAt := repo.store.now()
+	transfer.Status = status
+	transfer.CompletedAt = &completedAt
+	repo.store.transfers[id] = transfer
+
+	transfer = repo.store.withProductNames(cloneTransfer(transfer))
+	return &transfer, nil
+}
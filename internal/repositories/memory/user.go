@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+)
+
+type UserRepo struct {
+	store *Store
+}
+
+func NewUserRepo(store *Store) *UserRepo {
+	return &UserRepo{store: store}
+}
+
+func (repo *UserRepo) GetAll(ctx context.Context) ([]models.User, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	users := make([]models.User, 0, len(repo.store.users))
+	for _, user := range repo.store.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	return users, nil
+}
+
+func (repo *UserRepo) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	for _, user := range repo.store.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+
+	return nil, apperrors.NotFound("user_not_found", "User not found")
+}
+
+func (repo *UserRepo) Create(ctx context.Context, user *models.User) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	// same guarantee as the users.username unique constraint
+	for _, existing := range repo.store.users {
+		if existing.Username == user.Username {
+			return apperrors.Conflict("username_taken", "Username already exists")
+		}
+	}
+
+	repo.store.lastUserID++
+	user.ID = repo.store.lastUserID
+	user.CreatedAt = repo.store.now()
+	repo.store.users[user.ID] = *user
+
+	return nil
+}
+
+func (repo *UserRepo) Count(ctx context.Context) (int, error) {
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	return len(repo.store.users), nil
+}
@@ -1,9 +1,16 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"store-api-go/internal/apperrors"
 	"store-api-go/internal/models"
+	"store-api-go/internal/pagination"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type ProductRepo struct {
@@ -14,61 +21,201 @@ func NewProductRepo(db *sql.DB) *ProductRepo {
 	return &ProductRepo{db: db}
 }
 
-func (repo *ProductRepo) GetAll(name string) ([]models.Product, error) {
-	query := "SELECT id, name, price, stock FROM products"
+const productSelect = `SELECT p.id, p.name, p.price, p.stock, p.category_id, COALESCE(c.name, ''), p.tax_class_id
+	FROM products p
+	LEFT JOIN categories c ON c.id = p.category_id`
+
+func scanProduct(row interface{ Scan(...any) error }, product *models.Product) error {
+	var categoryID, taxClassID sql.NullInt64
+	err := row.Scan(&product.ID, &product.Name, &product.Price, &product.Stock, &categoryID, &product.CategoryName, &taxClassID)
+	if err != nil {
+		return err
+	}
+
+	product.CategoryID = nil
+	if categoryID.Valid {
+		id := int(categoryID.Int64)
+		product.CategoryID = &id
+	}
+
+	product.TaxClassID = nil
+	if taxClassID.Valid {
+		id := int(taxClassID.Int64)
+		product.TaxClassID = &id
+	}
+
+	return nil
+}
+
+// productAtLocationSelect reports the stock at the location bound to $1 instead of the total
+const productAtLocationSelect = `SELECT p.id, p.name, p.price, COALESCE(ls.quantity, 0), p.category_id, COALESCE(c.name, ''),
+		p.tax_class_id
+	FROM products p
+	LEFT JOIN categories c ON c.id = p.category_id
+	LEFT JOIN product_stocks ls ON ls.product_id = p.id AND ls.location_id = $1`
+
+var productColumns = map[string]string{
+	"id":    "p.id",
+	"name":  "p.name",
+	"price": "p.price",
+	"stock": "p.stock",
+}
+
+var productAtLocationColumns = map[string]string{
+	"id":    "p.id",
+	"name":  "p.name",
+	"price": "p.price",
+	"stock": "COALESCE(ls.quantity, 0)",
+}
+
+func (repo *ProductRepo) GetAll(ctx context.Context, filter models.ProductFilter) ([]models.Product, string, error) {
+	query := productSelect
+	columns := productColumns
 
+	var conditions []string
 	var args []interface{}
-	if name != "" {
-		query += " WHERE name ILIKE $1"
-		args = append(args, "%"+name+"%")
+	if filter.LocationID != 0 {
+		query = productAtLocationSelect
+		columns = productAtLocationColumns
+		args = append(args, filter.LocationID)
 	}
+	if filter.Name != "" {
+		args = append(args, "%"+filter.Name+"%")
+		conditions = append(conditions, fmt.Sprintf("p.name ILIKE $%d", len(args)))
+	}
+	if filter.CategoryID != 0 {
+		args = append(args, filter.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("p.category_id = $%d", len(args)))
+	}
+	if filter.MinPrice != 0 {
+		args = append(args, filter.MinPrice)
+		conditions = append(conditions, fmt.Sprintf("p.price >= $%d", len(args)))
+	}
+	if filter.MaxPrice != 0 {
+		args = append(args, filter.MaxPrice)
+		conditions = append(conditions, fmt.Sprintf("p.price <= $%d", len(args)))
+	}
+	if filter.InStock != nil {
+		if *filter.InStock {
+			conditions = append(conditions, columns["stock"]+" > 0")
+		} else {
+			conditions = append(conditions, columns["stock"]+" <= 0")
+		}
+	}
+	if filter.Cursor != "" {
+		after, err := pagination.DecodeCursor(filter.Sort, filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		conditions = append(conditions, keysetCondition(filter.Sort, columns, after, &args))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// fetch one extra row to know whether there is a next page
+	args = append(args, filter.Limit+1)
+	query += orderBy(filter.Sort, columns) + fmt.Sprintf(" LIMIT $%d", len(args))
 
-	rows, err := repo.db.Query(query, args...)
+	rows, err := repo.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
 	products := make([]models.Product, 0)
 	for rows.Next() {
 		var product models.Product
-		err := rows.Scan(&product.ID, &product.Name, &product.Price, &product.Stock)
+		err := scanProduct(rows, &product)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		products = append(products, product)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	products, next := pagination.Trim(products, filter.Sort, filter.Limit)
 
-	return products, nil
+	if filter.StockByLocation {
+		productIDs := make([]int, len(products))
+		for i := range products {
+			productIDs[i] = products[i].ID
+		}
+
+		stocks, err := loadProductStocks(ctx, repo.db, productIDs)
+		if err != nil {
+			return nil, "", err
+		}
+		for i := range products {
+			products[i].Stocks = stocks[products[i].ID]
+		}
+	}
+
+	return products, next, nil
 }
 
-func (repo *ProductRepo) Create(product *models.Product) error {
-	query := "INSERT INTO products (name, price, stock) VALUES ($1, $2, $3) RETURNING id"
-	err := repo.db.QueryRow(query, product.Name, product.Price, product.Stock).Scan(&product.ID)
+// Create puts the product's opening stock at locationID
+func (repo *ProductRepo) Create(ctx context.Context, product *models.Product, locationID int, actor string) error {
+	dbTransaction, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.Rollback()
 
-	return err
+	query := "INSERT INTO products (name, price, stock, category_id, tax_class_id) VALUES ($1, $2, 0, $3, $4) RETURNING id"
+	err = dbTransaction.QueryRowContext(ctx, query, product.Name, product.Price, product.CategoryID, product.TaxClassID).Scan(&product.ID)
+	if err != nil {
+		return nameTaken(err, "idx_products_name_lower")
+	}
+
+	if product.Stock != 0 {
+		movement := models.StockMovement{
+			ProductID:     product.ID,
+			LocationID:    locationID,
+			Reason:        models.StockReasonAdjustment,
+			QuantityDelta: product.Stock,
+			Actor:         actor,
+			Note:          "opening balance",
+		}
+		if err := applyStock(ctx, dbTransaction, &movement); err != nil {
+			return err
+		}
+		if err := insertMovement(ctx, dbTransaction, &movement); err != nil {
+			return err
+		}
+	}
+
+	return dbTransaction.Commit()
 }
 
-func (repo *ProductRepo) GetByID(id int) (*models.Product, error) {
-	query := "SELECT id, name, price, stock FROM products WHERE id = $1"
+func (repo *ProductRepo) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	query := productSelect + " WHERE p.id = $1"
 
 	var product models.Product
-	err := repo.db.QueryRow(query, id).Scan(&product.ID, &product.Name, &product.Price, &product.Stock)
+	err := scanProduct(repo.db.QueryRowContext(ctx, query, id), &product)
 	if err == sql.ErrNoRows {
-		return nil, errors.New("Product not found")
+		return nil, apperrors.NotFound("product_not_found", "Product not found")
 	}
 	if err != nil {
 		return nil, err
 	}
 
+	stocks, err := loadProductStocks(ctx, repo.db, []int{id})
+	if err != nil {
+		return nil, err
+	}
+	product.Stocks = stocks[id]
+
 	return &product, nil
 }
 
-func (repo *ProductRepo) Update(product *models.Product) error {
-	query := "UPDATE products SET name = $1, price = $2, stock = $3, WHERE id = $4"
-	result, err := repo.db.Exec(query, product.Name, product.Price, product.Stock, product.ID)
+func (repo *ProductRepo) Update(ctx context.Context, product *models.Product) error {
+	query := "UPDATE products SET name = $1, price = $2, category_id = $3, tax_class_id = $4 WHERE id = $5"
+	result, err := repo.db.ExecContext(ctx, query, product.Name, product.Price, product.CategoryID, product.TaxClassID, product.ID)
 	if err != nil {
-		return err
+		return nameTaken(err, "idx_products_name_lower")
 	}
 
 	rows, err := result.RowsAffected()
@@ -77,17 +224,55 @@ func (repo *ProductRepo) Update(product *models.Product) error {
 	}
 
 	if rows == 0 {
-		return errors.New("Product not found")
+		return apperrors.NotFound("product_not_found", "Product not found")
 	}
 
 	return nil
 }
 
-func (repo *ProductRepo) Delete(id int) error {
+// nameTaken turns a violation of the unique name index into NameTaken, for writes
+// that raced past the services' check
+func nameTaken(err error, index string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == index {
+		return NameTaken()
+	}
+
+	return err
+}
+
+// productInUse turns the foreign key violation of deleting a product that is still
+// referenced into a conflict naming the referencing table
+func productInUse(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgForeignKeyViolation {
+		return err
+	}
+
+	switch pgErr.TableName {
+	case "stock_transfer_items":
+		return apperrors.Conflict("product_in_use", "Product is referenced by transfers")
+	case "purchase_order_lines":
+		return apperrors.Conflict("product_in_use", "Product is referenced by purchase orders")
+	default:
+		return apperrors.Conflict("product_in_use", "Product is referenced by transactions")
+	}
+}
+
+func (repo *ProductRepo) NameExists(ctx context.Context, name string, excludeID int) (bool, error) {
+	var exists bool
+	err := repo.db.QueryRowContext(
+		ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE LOWER(name) = LOWER($1) AND id <> $2)", name, excludeID,
+	).Scan(&exists)
+
+	return exists, err
+}
+
+func (repo *ProductRepo) Delete(ctx context.Context, id int) error {
 	query := "DELETE FROM products WHERE id = $1"
-	result, err := repo.db.Exec(query, id)
+	result, err := repo.db.ExecContext(ctx, query, id)
 	if err != nil {
-		return err
+		return productInUse(err)
 	}
 	rows, err := result.RowsAffected()
 	if err != nil {
@@ -95,7 +280,7 @@ func (repo *ProductRepo) Delete(id int) error {
 	}
 
 	if rows == 0 {
-		return errors.New("Product not found")
+		return apperrors.NotFound("product_not_found", "Product not found")
 	}
 
 	return err
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type PromotionRepo struct {
+	db *sql.DB
+}
+
+func NewPromotionRepo(db *sql.DB) *PromotionRepo {
+	return &PromotionRepo{db: db}
+}
+
+const promotionSelect = `SELECT id, name, type, value, buy_quantity, get_quantity, product_id, category_id,
+		COALESCE(coupon_code, ''), starts_at, ends_at, active, created_at
+	FROM promotions`
+
+func scanPromotion(row interface{ Scan(...any) error }, promotion *models.Promotion) error {
+	var productID, categoryID sql.NullInt64
+	var startsAt, endsAt sql.NullTime
+	var active bool
+	err := row.Scan(&promotion.ID, &promotion.Name, &promotion.Type, &promotion.Value, &promotion.BuyQuantity,
+		&promotion.GetQuantity, &productID, &categoryID, &promotion.CouponCode, &startsAt, &endsAt,
+		&active, &promotion.CreatedAt)
+	if err != nil {
+		return err
+	}
+	promotion.Active = &active
+
+	promotion.ProductID, promotion.CategoryID = nil, nil
+	if productID.Valid {
+		id := int(productID.Int64)
+		promotion.ProductID = &id
+	}
+	if categoryID.Valid {
+		id := int(categoryID.Int64)
+		promotion.CategoryID = &id
+	}
+
+	promotion.StartsAt, promotion.EndsAt = nil, nil
+	if startsAt.Valid {
+		promotion.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		promotion.EndsAt = &endsAt.Time
+	}
+
+	return nil
+}
+
+func (repo *PromotionRepo) query(ctx context.Context, query string, args ...any) ([]models.Promotion, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := make([]models.Promotion, 0)
+	for rows.Next() {
+		var promotion models.Promotion
+		if err := scanPromotion(rows, &promotion); err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, promotion)
+	}
+
+	return promotions, rows.Err()
+}
+
+func (repo *PromotionRepo) GetAll(ctx context.Context) ([]models.Promotion, error) {
+	return repo.query(ctx, promotionSelect+" ORDER BY id")
+}
+
+func (repo *PromotionRepo) GetRunning(ctx context.Context, now time.Time) ([]models.Promotion, error) {
+	return repo.query(ctx, promotionSelect+`
+		WHERE active AND (starts_at IS NULL OR starts_at <= $1) AND (ends_at IS NULL OR ends_at > $1)
+		ORDER BY id`, now)
+}
+
+func (repo *PromotionRepo) GetByID(ctx context.Context, id int) (*models.Promotion, error) {
+	var promotion models.Promotion
+	err := scanPromotion(repo.db.QueryRowContext(ctx, promotionSelect+" WHERE id = $1", id), &promotion)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NotFound("promotion_not_found", "Promotion not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &promotion, nil
+}
+
+// couponCode stores promotions without a code as NULL so the unique constraint only covers real codes
+func couponCode(promotion *models.Promotion) any {
+	if promotion.CouponCode == "" {
+		return nil
+	}
+
+	return promotion.CouponCode
+}
+
+func (repo *PromotionRepo) Create(ctx context.Context, promotion *models.Promotion) error {
+	query := `INSERT INTO promotions (name, type, value, buy_quantity, get_quantity, product_id, category_id,
+			coupon_code, starts_at, ends_at, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, created_at`
+	err := repo.db.QueryRowContext(ctx, query, promotion.Name, promotion.Type, promotion.Value, promotion.BuyQuantity,
+		promotion.GetQuantity, promotion.ProductID, promotion.CategoryID, couponCode(promotion),
+		promotion.StartsAt, promotion.EndsAt, promotion.Active,
+	).Scan(&promotion.ID, &promotion.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return apperrors.Conflict("coupon_code_taken", "Coupon code already exists")
+	}
+
+	return err
+}
+
+func (repo *PromotionRepo) Update(ctx context.Context, promotion *models.Promotion) error {
+	query := `UPDATE promotions SET name = $1, type = $2, value = $3, buy_quantity = $4, get_quantity = $5,
+			product_id = $6, category_id = $7, coupon_code = $8, starts_at = $9, ends_at = $10, active = $11
+		WHERE id = $12 RETURNING created_at`
+	err := repo.db.QueryRowContext(ctx, query, promotion.Name, promotion.Type, promotion.Value, promotion.BuyQuantity,
+		promotion.GetQuantity, promotion.ProductID, promotion.CategoryID, couponCode(promotion),
+		promotion.StartsAt, promotion.EndsAt, promotion.Active, promotion.ID,
+	).Scan(&promotion.CreatedAt)
+	if err == sql.ErrNoRows {
+		return apperrors.NotFound("promotion_not_found", "Promotion not found")
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return apperrors.Conflict("coupon_code_taken", "Coupon code already exists")
+	}
+
+	return err
+}
+
+// Delete keeps past sales intact, their lines lose the link to the promotion but keep the discount
+func (repo *PromotionRepo) Delete(ctx context.Context, id int) error {
+	result, err := repo.db.ExecContext(ctx, "DELETE FROM promotions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return apperrors.NotFound("promotion_not_found", "Promotion not found")
+	}
+
+	return nil
+}
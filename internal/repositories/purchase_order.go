@@ -0,0 +1,377 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type PurchaseOrderRepo struct {
+	db *sql.DB
+}
+
+func NewPurchaseOrderRepo(db *sql.DB) *PurchaseOrderRepo {
+	return &PurchaseOrderRepo{db: db}
+}
+
+const purchaseOrderSelect = `SELECT po.id, po.supplier_id, s.name, po.location_id, po.status, po.note, po.created_by,
+		po.created_at, po.sent_at, po.closed_at,
+		COALESCE((SELECT SUM(l.quantity_ordered * l.unit_cost) FROM purchase_order_lines l WHERE l.purchase_order_id = po.id), 0)
+	FROM purchase_orders po
+	JOIN suppliers s ON s.id = po.supplier_id`
+
+func scanPurchaseOrder(row interface{ Scan(...any) error }, order *models.PurchaseOrder) error {
+	var sentAt, closedAt sql.NullTime
+	err := row.Scan(&order.ID, &order.SupplierID, &order.SupplierName, &order.LocationID, &order.Status, &order.Note,
+		&order.CreatedBy, &order.CreatedAt, &sentAt, &closedAt, &order.TotalCost)
+	if err != nil {
+		return err
+	}
+
+	order.SentAt, order.ClosedAt = nil, nil
+	if sentAt.Valid {
+		order.SentAt = &sentAt.Time
+	}
+	if closedAt.Valid {
+		order.ClosedAt = &closedAt.Time
+	}
+
+	return nil
+}
+
+func loadPurchaseOrderLines(ctx context.Context, q querier, orderID int) ([]models.PurchaseOrderLine, error) {
+	query := `SELECT l.id, l.product_id, p.name, l.quantity_ordered, l.quantity_received, l.unit_cost
+		FROM purchase_order_lines l
+		JOIN products p ON p.id = l.product_id
+		WHERE l.purchase_order_id = $1
+		ORDER BY l.id`
+	rows, err := q.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := make([]models.PurchaseOrderLine, 0)
+	for rows.Next() {
+		line := models.PurchaseOrderLine{PurchaseOrderID: orderID}
+		err := rows.Scan(&line.ID, &line.ProductID, &line.ProductName, &line.QuantityOrdered, &line.QuantityReceived, &line.UnitCost)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+func loadGoodsReceipts(ctx context.Context, q querier, orderID int) ([]models.GoodsReceipt, error) {
+	query := `SELECT r.id, r.note, r.received_by, r.created_at, ri.id, ri.line_id, l.product_id, ri.quantity
+		FROM goods_receipts r
+		JOIN goods_receipt_items ri ON ri.receipt_id = r.id
+		JOIN purchase_order_lines l ON l.id = ri.line_id
+		WHERE r.purchase_order_id = $1
+		ORDER BY r.id, ri.id`
+	rows, err := q.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := make([]models.GoodsReceipt, 0)
+	for rows.Next() {
+		receipt := models.GoodsReceipt{PurchaseOrderID: orderID}
+		var item models.GoodsReceiptItem
+		err := rows.Scan(&receipt.ID, &receipt.Note, &receipt.ReceivedBy, &receipt.CreatedAt,
+			&item.ID, &item.LineID, &item.ProductID, &item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		item.ReceiptID = receipt.ID
+
+		// rows are ordered by receipt, so items of the same receipt are adjacent
+		if last := len(receipts) - 1; last >= 0 && receipts[last].ID == receipt.ID {
+			receipts[last].Items = append(receipts[last].Items, item)
+			continue
+		}
+		receipt.Items = []models.GoodsReceiptItem{item}
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, rows.Err()
+}
+
+func (repo *PurchaseOrderRepo) GetAll(ctx context.Context, filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, int, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("po.status = $%d", len(args)))
+	}
+	if filter.SupplierID != 0 {
+		args = append(args, filter.SupplierID)
+		conditions = append(conditions, fmt.Sprintf("po.supplier_id = $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM purchase_orders po"+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query := fmt.Sprintf("%s%s ORDER BY po.id DESC LIMIT $%d OFFSET $%d", purchaseOrderSelect, where, len(args)-1, len(args))
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	orders := make([]models.PurchaseOrder, 0)
+	for rows.Next() {
+		var order models.PurchaseOrder
+		if err := scanPurchaseOrder(rows, &order); err != nil {
+			return nil, 0, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, total, rows.Err()
+}
+
+func (repo *PurchaseOrderRepo) GetByID(ctx context.Context, id int) (*models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	err := scanPurchaseOrder(repo.db.QueryRowContext(ctx, purchaseOrderSelect+" WHERE po.id = $1", id), &order)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NotFound("purchase_order_not_found", "Purchase order not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	order.Lines, err = loadPurchaseOrderLines(ctx, repo.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Receipts, err = loadGoodsReceipts(ctx, repo.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (repo *PurchaseOrderRepo) Create(ctx context.Context, order *models.PurchaseOrder) error {
+	dbTransaction, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.Rollback()
+
+	order.Status = models.PurchaseOrderDraft
+	err = dbTransaction.QueryRowContext(
+		ctx, `INSERT INTO purchase_orders (supplier_id, location_id, status, note, created_by)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		order.SupplierID, order.LocationID, order.Status, order.Note, order.CreatedBy,
+	).Scan(&order.ID, &order.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		line.PurchaseOrderID = order.ID
+
+		err = dbTransaction.QueryRowContext(
+			ctx, `INSERT INTO purchase_order_lines (purchase_order_id, product_id, quantity_ordered, unit_cost)
+			VALUES ($1, $2, $3, $4) RETURNING id`,
+			line.PurchaseOrderID, line.ProductID, line.QuantityOrdered, line.UnitCost,
+		).Scan(&line.ID)
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return apperrors.NotFound("product_not_found", "product id %d not found", line.ProductID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return dbTransaction.Commit()
+}
+
+// lockPurchaseOrder loads the order with its lines, holding its row lock for the rest of
+// the transaction
+func lockPurchaseOrder(ctx context.Context, dbTransaction *sql.Tx, id int) (*models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	err := scanPurchaseOrder(dbTransaction.QueryRowContext(ctx, purchaseOrderSelect+" WHERE po.id = $1 FOR UPDATE OF po", id), &order)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NotFound("purchase_order_not_found", "Purchase order not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	order.Lines, err = loadPurchaseOrderLines(ctx, dbTransaction, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// saveStatus stores the order's status, stamping when it was sent or closed
+func saveStatus(ctx context.Context, dbTransaction *sql.Tx, order *models.PurchaseOrder) error {
+	_, err := dbTransaction.ExecContext(
+		ctx, `UPDATE purchase_orders SET status = $1,
+			sent_at = CASE WHEN $1 = 'sent' THEN now() ELSE sent_at END,
+			closed_at = CASE WHEN $1 = 'closed' THEN now() ELSE closed_at END
+		WHERE id = $2`,
+		order.Status, order.ID,
+	)
+
+	return err
+}
+
+func (repo *PurchaseOrderRepo) UpdateStatus(ctx context.Context, id int, transition PurchaseOrderFunc) (*models.PurchaseOrder, error) {
+	dbTransaction, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dbTransaction.Rollback()
+
+	order, err := lockPurchaseOrder(ctx, dbTransaction, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transition(order); err != nil {
+		return nil, err
+	}
+	if err := saveStatus(ctx, dbTransaction, order); err != nil {
+		return nil, err
+	}
+
+	if err := dbTransaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(ctx, id)
+}
+
+func (repo *PurchaseOrderRepo) Receive(ctx context.Context, id int, receipt *models.GoodsReceipt, plan ReceiptFunc) (*models.PurchaseOrder, error) {
+	dbTransaction, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dbTransaction.Rollback()
+
+	order, err := lockPurchaseOrder(ctx, dbTransaction, id)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt.PurchaseOrderID = id
+	if err := plan(order, receipt); err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]int, 0, len(receipt.Items))
+	for _, item := range receipt.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	if _, err := lockProducts(ctx, dbTransaction, productIDs); err != nil {
+		return nil, err
+	}
+
+	err = dbTransaction.QueryRowContext(
+		ctx, "INSERT INTO goods_receipts (purchase_order_id, note, received_by) VALUES ($1, $2, $3) RETURNING id, created_at",
+		receipt.PurchaseOrderID, receipt.Note, receipt.ReceivedBy,
+	).Scan(&receipt.ID, &receipt.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range receipt.Items {
+		item := &receipt.Items[i]
+		item.ReceiptID = receipt.ID
+
+		err = dbTransaction.QueryRowContext(
+			ctx, "INSERT INTO goods_receipt_items (receipt_id, line_id, quantity) VALUES ($1, $2, $3) RETURNING id",
+			item.ReceiptID, item.LineID, item.Quantity,
+		).Scan(&item.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = dbTransaction.ExecContext(
+			ctx, "UPDATE purchase_order_lines SET quantity_received = quantity_received + $1 WHERE id = $2", item.Quantity, item.LineID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		movement := models.StockMovement{
+			ProductID:     item.ProductID,
+			LocationID:    order.LocationID,
+			Reason:        models.StockReasonReceipt,
+			QuantityDelta: item.Quantity,
+			Actor:         receipt.ReceivedBy,
+			ReferenceID:   &receipt.ID,
+			Note:          fmt.Sprintf("purchase order %d", order.ID),
+		}
+		if err := applyStock(ctx, dbTransaction, &movement); err != nil {
+			return nil, err
+		}
+		if err := insertMovement(ctx, dbTransaction, &movement); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := saveStatus(ctx, dbTransaction, order); err != nil {
+		return nil, err
+	}
+
+	if err := dbTransaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(ctx, id)
+}
+
+func (repo *PurchaseOrderRepo) OnOrder(ctx context.Context) ([]models.OnOrderProduct, error) {
+	query := `SELECT l.product_id, p.name, SUM(l.quantity_ordered - l.quantity_received)
+		FROM purchase_order_lines l
+		JOIN purchase_orders po ON po.id = l.purchase_order_id
+		JOIN products p ON p.id = l.product_id
+		WHERE po.status IN ('sent', 'partially_received') AND l.quantity_received < l.quantity_ordered
+		GROUP BY l.product_id, p.name
+		ORDER BY p.name, l.product_id`
+	rows, err := repo.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]models.OnOrderProduct, 0)
+	for rows.Next() {
+		var product models.OnOrderProduct
+		if err := rows.Scan(&product.ProductID, &product.ProductName, &product.Quantity); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, rows.Err()
+}
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"time"
+)
+
+// Repository contracts used by the services. The Postgres implementations live in
+// this package, the in-memory ones in the memory sub package. Every method takes the
+// request's context, so queries stop when the request is cancelled.
+//
+// Keyset paginated GetAll methods return one page in the filter's sort order plus
+// the cursor of the next page, which is empty on the last page.
+
+// NameTaken is the error Create and Update return when another product or category
+// already has the name, ignoring case. It matches the failed "unique" rule the
+// services report when they find the name taken before writing.
+func NameTaken() error {
+	var errs apperrors.FieldErrors
+	errs.Add("name", "unique", "name is already taken")
+
+	return errs.Err()
+}
+
+type CategoryRepository interface {
+	GetAll(ctx context.Context, filter models.CategoryFilter) ([]models.Category, string, error)
+	Create(ctx context.Context, category *models.Category) error
+	GetByID(ctx context.Context, id int) (*models.Category, error)
+	Update(ctx context.Context, category *models.Category) error
+	Delete(ctx context.Context, id int) error
+	// DeleteWithProducts deletes the category and its products, DeleteDetachingProducts
+	// deletes it after clearing it from its products
+	DeleteWithProducts(ctx context.Context, id int) error
+	DeleteDetachingProducts(ctx context.Context, id int) error
+	// NameExists reports whether another row than excludeID has the name, ignoring case
+	NameExists(ctx context.Context, name string, excludeID int) (bool, error)
+}
+
+// StockFunc runs with the product's stock at the movement's location locked and
+// returns the change to apply to it
+type StockFunc func(current int) (delta int, err error)
+
+// ProductRepository.Update never touches stock. Stock only changes through Create,
+// AdjustStock, checkout, refunds and transfers, which all write the stock ledger.
+// products.stock is kept equal to the sum of the product's per location stock.
+type ProductRepository interface {
+	GetAll(ctx context.Context, filter models.ProductFilter) ([]models.Product, string, error)
+	Create(ctx context.Context, product *models.Product, locationID int, actor string) error
+	GetByID(ctx context.Context, id int) (*models.Product, error)
+	Update(ctx context.Context, product *models.Product) error
+	Delete(ctx context.Context, id int) error
+	NameExists(ctx context.Context, name string, excludeID int) (bool, error)
+	AdjustStock(ctx context.Context, movement *models.StockMovement, adjust StockFunc) error
+	GetStockHistory(ctx context.Context, productID int, page int, limit int) ([]models.StockMovement, int, error)
+}
+
+// SettleFunc runs inside the checkout once every line is priced and stock is reserved.
+// It fills in the totals and payments, returning an error aborts the checkout.
+type SettleFunc func(transaction *models.Transaction) error
+
+// RefundFunc runs inside the refund once the transaction is locked. The transaction's
+// details carry the quantity and amount already refunded, it must fill in the refund
+// items and amount or return an error to abort.
+type RefundFunc func(transaction *models.Transaction, refund *models.Refund) error
+
+type TransactionRepository interface {
+	GetAll(ctx context.Context, filter models.TransactionFilter) ([]models.Transaction, int, error)
+	GetByID(ctx context.Context, id int) (*models.Transaction, error)
+	CreateTransaction(ctx context.Context, request models.CheckoutRequest, actor string, settle SettleFunc) (*models.Transaction, error)
+	CreateRefund(ctx context.Context, transactionID int, request models.RefundRequest, actor string, plan RefundFunc) (*models.Refund, error)
+	Report(ctx context.Context, startDate string, endDate string) (*models.ReportResponse, error)
+	TaxReport(ctx context.Context, startDate string, endDate string) (*models.TaxReport, error)
+}
+
+type LocationRepository interface {
+	GetAll(ctx context.Context) ([]models.Location, error)
+	GetByID(ctx context.Context, id int) (*models.Location, error)
+	Create(ctx context.Context, location *models.Location) error
+	Update(ctx context.Context, location *models.Location) error
+}
+
+// TransferRepository.Create takes the stock out of the source location. Complete moves
+// an in transit transfer to received, stocking the destination, or to cancelled,
+// returning the stock to the source.
+type TransferRepository interface {
+	GetAll(ctx context.Context, status string) ([]models.StockTransfer, error)
+	GetByID(ctx context.Context, id int) (*models.StockTransfer, error)
+	Create(ctx context.Context, transfer *models.StockTransfer) error
+	Complete(ctx context.Context, id int, status string, actor string) (*models.StockTransfer, error)
+}
+
+type SupplierRepository interface {
+	GetAll(ctx context.Context) ([]models.Supplier, error)
+	GetByID(ctx context.Context, id int) (*models.Supplier, error)
+	Create(ctx context.Context, supplier *models.Supplier) error
+	Update(ctx context.Context, supplier *models.Supplier) error
+	Delete(ctx context.Context, id int) error
+}
+
+// PurchaseOrderFunc runs with the purchase order locked and changes its status
+type PurchaseOrderFunc func(order *models.PurchaseOrder) error
+
+// ReceiptFunc runs with the purchase order and its lines locked, it fills in the
+// receipt's items and the order's new status. The repository then books the items
+// into stock at the order's location.
+type ReceiptFunc func(order *models.PurchaseOrder, receipt *models.GoodsReceipt) error
+
+type PurchaseOrderRepository interface {
+	GetAll(ctx context.Context, filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, int, error)
+	GetByID(ctx context.Context, id int) (*models.PurchaseOrder, error)
+	Create(ctx context.Context, order *models.PurchaseOrder) error
+	UpdateStatus(ctx context.Context, id int, transition PurchaseOrderFunc) (*models.PurchaseOrder, error)
+	Receive(ctx context.Context, id int, receipt *models.GoodsReceipt, plan ReceiptFunc) (*models.PurchaseOrder, error)
+	OnOrder(ctx context.Context) ([]models.OnOrderProduct, error)
+}
+
+// PromotionRepository.GetRunning returns the active promotions whose time window
+// includes now, with or without coupon codes
+type PromotionRepository interface {
+	GetAll(ctx context.Context) ([]models.Promotion, error)
+	GetRunning(ctx context.Context, now time.Time) ([]models.Promotion, error)
+	GetByID(ctx context.Context, id int) (*models.Promotion, error)
+	Create(ctx context.Context, promotion *models.Promotion) error
+	Update(ctx context.Context, promotion *models.Promotion) error
+	Delete(ctx context.Context, id int) error
+}
+
+// TaxClassRepository.Delete refuses tax classes still used by products or past sales
+type TaxClassRepository interface {
+	GetAll(ctx context.Context) ([]models.TaxClass, error)
+	GetByID(ctx context.Context, id int) (*models.TaxClass, error)
+	Create(ctx context.Context, taxClass *models.TaxClass) error
+	Update(ctx context.Context, taxClass *models.TaxClass) error
+	Delete(ctx context.Context, id int) error
+}
+
+// IdempotencyRepository.Reserve stores the record as a pending reservation, setting its
+// CreatedAt, and returns nil, unless the user already holds the key. Then the existing
+// record is returned, pending or not, until it expires and is taken over. Complete
+// saves the response with its new ExpiresAt and Release drops the reservation so the
+// key can be retried, both only while the reservation is still the one the record made.
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, record *models.IdempotencyKey) (*models.IdempotencyKey, error)
+	Complete(ctx context.Context, record *models.IdempotencyKey) error
+	Release(ctx context.Context, record *models.IdempotencyKey) error
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+type UserRepository interface {
+	GetAll(ctx context.Context) ([]models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	Count(ctx context.Context) (int, error)
+}
+
+var (
+	_ UserRepository          = (*UserRepo)(nil)
+	_ LocationRepository      = (*LocationRepo)(nil)
+	_ TransferRepository      = (*TransferRepo)(nil)
+	_ SupplierRepository      = (*SupplierRepo)(nil)
+	_ PurchaseOrderRepository = (*PurchaseOrderRepo)(nil)
+	_ PromotionRepository     = (*PromotionRepo)(nil)
+	_ TaxClassRepository      = (*TaxClassRepo)(nil)
+	_ IdempotencyRepository   = (*IdempotencyRepo)(nil)
+	_ CategoryRepository      = (*CategoryRepo)(nil)
+	_ ProductRepository       = (*ProductRepo)(nil)
+	_ TransactionRepository   = (*TransactionRepo)(nil)
+)
@@ -0,0 +1,208 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"strings"
+)
+
+// insertMovement writes a stock ledger entry, it must run in the same database
+// transaction as the stock change it records
+func insertMovement(ctx context.Context, dbTransaction *sql.Tx, movement *models.StockMovement) error {
+	query := `INSERT INTO stock_movements (product_id, location_id, reason, quantity_delta, balance_after, actor, reference_id, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at`
+
+	return dbTransaction.QueryRowContext(ctx, query,
+		movement.ProductID, movement.LocationID, movement.Reason, movement.QuantityDelta, movement.BalanceAfter,
+		movement.Actor, movement.ReferenceID, movement.Note,
+	).Scan(&movement.ID, &movement.CreatedAt)
+}
+
+// applyStock adds movement.QuantityDelta to the product's stock at movement.LocationID
+// and to its total, and sets BalanceAfter to the location's new stock. A change that
+// would take the location below zero fails as out of stock. The caller records the
+// movement once it knows the reference.
+func applyStock(ctx context.Context, dbTransaction *sql.Tx, movement *models.StockMovement) error {
+	_, err := dbTransaction.ExecContext(
+		ctx, "INSERT INTO product_stocks (product_id, location_id, quantity) VALUES ($1, $2, 0) ON CONFLICT (product_id, location_id) DO NOTHING",
+		movement.ProductID, movement.LocationID,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = dbTransaction.QueryRowContext(
+		ctx, `UPDATE product_stocks SET quantity = quantity + $1
+		WHERE product_id = $2 AND location_id = $3 AND quantity + $1 >= 0
+		RETURNING quantity`,
+		movement.QuantityDelta, movement.ProductID, movement.LocationID,
+	).Scan(&movement.BalanceAfter)
+	if err == sql.ErrNoRows {
+		return apperrors.InsufficientStock("out_of_stock", "product id %d is out of stock", movement.ProductID)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = dbTransaction.ExecContext(ctx, "UPDATE products SET stock = stock + $1 WHERE id = $2", movement.QuantityDelta, movement.ProductID)
+
+	return err
+}
+
+// lockProducts locks the products in id order, like checkout, so stock changes touching
+// several products can't deadlock each other. It returns the product names.
+func lockProducts(ctx context.Context, dbTransaction *sql.Tx, productIDs []int) (map[int]string, error) {
+	productIDs = append([]int(nil), productIDs...)
+	sort.Ints(productIDs)
+
+	placeHolder := make([]string, len(productIDs))
+	args := make([]interface{}, len(productIDs))
+	for i, productID := range productIDs {
+		placeHolder[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = productID
+	}
+
+	query := fmt.Sprintf("SELECT id, name FROM products WHERE id IN (%s) ORDER BY id FOR UPDATE", strings.Join(placeHolder, ", "))
+	rows, err := dbTransaction.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		names[id] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, productID := range productIDs {
+		if _, exists := names[productID]; !exists {
+			return nil, apperrors.NotFound("product_not_found", "product id %d not found", productID)
+		}
+	}
+
+	return names, nil
+}
+
+// loadProductStocks returns the per location stock of the given products
+func loadProductStocks(ctx context.Context, q querier, productIDs []int) (map[int][]models.ProductStock, error) {
+	stocks := make(map[int][]models.ProductStock)
+	if len(productIDs) == 0 {
+		return stocks, nil
+	}
+
+	placeHolder := make([]string, len(productIDs))
+	args := make([]interface{}, len(productIDs))
+	for i, productID := range productIDs {
+		placeHolder[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = productID
+	}
+
+	query := fmt.Sprintf(`SELECT ps.product_id, ps.location_id, l.name, ps.quantity
+		FROM product_stocks ps
+		JOIN locations l ON l.id = ps.location_id
+		WHERE ps.product_id IN (%s)
+		ORDER BY ps.product_id, ps.location_id`, strings.Join(placeHolder, ", "))
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID int
+		var stock models.ProductStock
+		if err := rows.Scan(&productID, &stock.LocationID, &stock.LocationName, &stock.Quantity); err != nil {
+			return nil, err
+		}
+		stocks[productID] = append(stocks[productID], stock)
+	}
+
+	return stocks, rows.Err()
+}
+
+func (repo *ProductRepo) AdjustStock(ctx context.Context, movement *models.StockMovement, adjust StockFunc) error {
+	dbTransaction, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.Rollback()
+
+	// the product row lock serialises every stock change of the product
+	var current int
+	query := `SELECT COALESCE(ps.quantity, 0)
+		FROM products p
+		LEFT JOIN product_stocks ps ON ps.product_id = p.id AND ps.location_id = $2
+		WHERE p.id = $1
+		FOR UPDATE OF p`
+	err = dbTransaction.QueryRowContext(ctx, query, movement.ProductID, movement.LocationID).Scan(&current)
+	if err == sql.ErrNoRows {
+		return apperrors.NotFound("product_not_found", "Product not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	delta, err := adjust(current)
+	if err != nil {
+		return err
+	}
+
+	movement.QuantityDelta = delta
+	if err := applyStock(ctx, dbTransaction, movement); err != nil {
+		return err
+	}
+	if err := insertMovement(ctx, dbTransaction, movement); err != nil {
+		return err
+	}
+
+	return dbTransaction.Commit()
+}
+
+func (repo *ProductRepo) GetStockHistory(ctx context.Context, productID int, page int, limit int) ([]models.StockMovement, int, error) {
+	var total int
+	err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stock_movements WHERE product_id = $1", productID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT id, product_id, location_id, reason, quantity_delta, balance_after, actor, reference_id, note, created_at
+		FROM stock_movements
+		WHERE product_id = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3`
+	rows, err := repo.db.QueryContext(ctx, query, productID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := make([]models.StockMovement, 0)
+	for rows.Next() {
+		var movement models.StockMovement
+		var referenceID sql.NullInt64
+		err := rows.Scan(&movement.ID, &movement.ProductID, &movement.LocationID, &movement.Reason, &movement.QuantityDelta, &movement.BalanceAfter,
+			&movement.Actor, &referenceID, &movement.Note, &movement.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		if referenceID.Valid {
+			id := int(referenceID.Int64)
+			movement.ReferenceID = &id
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, total, rows.Err()
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type SupplierRepo struct {
+	db *sql.DB
+}
+
+func NewSupplierRepo(db *sql.DB) *SupplierRepo {
+	return &SupplierRepo{db: db}
+}
+
+const supplierSelect = "SELECT id, name, contact_name, phone, email, created_at FROM suppliers"
+
+func scanSupplier(row interface{ Scan(...any) error }, supplier *models.Supplier) error {
+	return row.Scan(&supplier.ID, &supplier.Name, &supplier.ContactName, &supplier.Phone, &supplier.Email, &supplier.CreatedAt)
+}
+
+func (repo *SupplierRepo) GetAll(ctx context.Context) ([]models.Supplier, error) {
+	rows, err := repo.db.QueryContext(ctx, supplierSelect+" ORDER BY name, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := make([]models.Supplier, 0)
+	for rows.Next() {
+		var supplier models.Supplier
+		if err := scanSupplier(rows, &supplier); err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, supplier)
+	}
+
+	return suppliers, rows.Err()
+}
+
+func (repo *SupplierRepo) GetByID(ctx context.Context, id int) (*models.Supplier, error) {
+	var supplier models.Supplier
+	err := scanSupplier(repo.db.QueryRowContext(ctx, supplierSelect+" WHERE id = $1", id), &supplier)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NotFound("supplier_not_found", "Supplier not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &supplier, nil
+}
+
+func (repo *SupplierRepo) Create(ctx context.Context, supplier *models.Supplier) error {
+	query := "INSERT INTO suppliers (name, contact_name, phone, email) VALUES ($1, $2, $3, $4) RETURNING id, created_at"
+	err := repo.db.QueryRowContext(ctx, query, supplier.Name, supplier.ContactName, supplier.Phone, supplier.Email).Scan(&supplier.ID, &supplier.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return apperrors.Conflict("supplier_name_taken", "Supplier name already exists")
+	}
+
+	return err
+}
+
+func (repo *SupplierRepo) Update(ctx context.Context, supplier *models.Supplier) error {
+	query := "UPDATE suppliers SET name = $1, contact_name = $2, phone = $3, email = $4 WHERE id = $5 RETURNING created_at"
+	err := repo.db.QueryRowContext(ctx, query, supplier.Name, supplier.ContactName, supplier.Phone, supplier.Email, supplier.ID).Scan(&supplier.CreatedAt)
+	if err == sql.ErrNoRows {
+		return apperrors.NotFound("supplier_not_found", "Supplier not found")
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return apperrors.Conflict("supplier_name_taken", "Supplier name already exists")
+	}
+
+	return err
+}
+
+func (repo *SupplierRepo) Delete(ctx context.Context, id int) error {
+	result, err := repo.db.ExecContext(ctx, "DELETE FROM suppliers WHERE id = $1", id)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return apperrors.Conflict("supplier_in_use", "Supplier has purchase orders")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return apperrors.NotFound("supplier_not_found", "Supplier not found")
+	}
+
+	return nil
+}
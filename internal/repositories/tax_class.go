@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type TaxClassRepo struct {
+	db *sql.DB
+}
+
+func NewTaxClassRepo(db *sql.DB) *TaxClassRepo {
+	return &TaxClassRepo{db: db}
+}
+
+func (repo *TaxClassRepo) GetAll(ctx context.Context) ([]models.TaxClass, error) {
+	rows, err := repo.db.QueryContext(ctx, "SELECT id, name, rate FROM tax_classes ORDER BY name, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taxClasses := make([]models.TaxClass, 0)
+	for rows.Next() {
+		var taxClass models.TaxClass
+		if err := rows.Scan(&taxClass.ID, &taxClass.Name, &taxClass.Rate); err != nil {
+			return nil, err
+		}
+		taxClasses = append(taxClasses, taxClass)
+	}
+
+	return taxClasses, rows.Err()
+}
+
+func (repo *TaxClassRepo) GetByID(ctx context.Context, id int) (*models.TaxClass, error) {
+	var taxClass models.TaxClass
+	err := repo.db.QueryRowContext(ctx, "SELECT id, name, rate FROM tax_classes WHERE id = $1", id).
+		Scan(&taxClass.ID, &taxClass.Name, &taxClass.Rate)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NotFound("tax_class_not_found", "Tax class not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &taxClass, nil
+}
+
+func (repo *TaxClassRepo) Create(ctx context.Context, taxClass *models.TaxClass) error {
+	err := repo.db.QueryRowContext(
+		ctx, "INSERT INTO tax_classes (name, rate) VALUES ($1, $2) RETURNING id", taxClass.Name, taxClass.Rate,
+	).Scan(&taxClass.ID)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return apperrors.Conflict("tax_class_name_taken", "Tax class name already exists")
+	}
+
+	return err
+}
+
+// Update only changes future sales, past lines keep the rate they were sold at
+func (repo *TaxClassRepo) Update(ctx context.Context, taxClass *models.TaxClass) error {
+	result, err := repo.db.ExecContext(ctx, "UPDATE tax_classes SET name = $1, rate = $2 WHERE id = $3",
+		taxClass.Name, taxClass.Rate, taxClass.ID)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return apperrors.Conflict("tax_class_name_taken", "Tax class name already exists")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return apperrors.NotFound("tax_class_not_found", "Tax class not found")
+	}
+
+	return nil
+}
+
+func (repo *TaxClassRepo) Delete(ctx context.Context, id int) error {
+	result, err := repo.db.ExecContext(ctx, "DELETE FROM tax_classes WHERE id = $1", id)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return apperrors.Conflict("tax_class_in_use", "Tax class is used by products or sales")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return apperrors.NotFound("tax_class_not_found", "Tax class not found")
+	}
+
+	return nil
+}
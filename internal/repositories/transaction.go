@@ -1,130 +1,718 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"store-api-go/internal/apperrors"
 	"store-api-go/internal/models"
 	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+const dateLayout = "2006-01-02"
+
 type TransactionRepo struct {
-	db *sql.DB
+	db       *sql.DB
+	location *time.Location
+}
+
+// NewTransactionRepo buckets sales and refunds into the days of location
+func NewTransactionRepo(db *sql.DB, location *time.Location) *TransactionRepo {
+	return &TransactionRepo{db: db, location: location}
+}
+
+// dayStart is the instant date begins in the repo's location
+func (repo *TransactionRepo) dayStart(date string) (time.Time, error) {
+	start, err := time.ParseInLocation(dateLayout, date, repo.location)
+	if err != nil {
+		return time.Time{}, apperrors.BadRequest("invalid_date", "Invalid date %q, expected YYYY-MM-DD", date)
+	}
+
+	return start, nil
+}
+
+// days returns the instants from startDate up to the end of endDate, for
+// created_at >= from AND created_at < to
+func (repo *TransactionRepo) days(startDate string, endDate string) (time.Time, time.Time, error) {
+	from, err := repo.dayStart(startDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := repo.dayStart(endDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// loadDetails returns the transaction's lines with product names and refunded totals
+func loadDetails(ctx context.Context, q querier, transactionID int) ([]models.TransactionDetail, error) {
+	query := `SELECT td.id, td.product_id, p.name, td.quantity, td.unit_price, td.gross_amount, td.discount_amount,
+			td.promotion_id, COALESCE(pr.name, ''), td.tax_class_id, td.tax_rate, td.tax_amount, td.subtotal,
+			COALESCE(SUM(ri.quantity), 0), COALESCE(SUM(ri.amount), 0), COALESCE(SUM(ri.tax_amount), 0)
+		FROM transaction_details td
+		JOIN products p ON p.id = td.product_id
+		LEFT JOIN promotions pr ON pr.id = td.promotion_id
+		LEFT JOIN refund_items ri ON ri.transaction_detail_id = td.id
+		WHERE td.transaction_id = $1
+		GROUP BY td.id, p.name, pr.name
+		ORDER BY td.id`
+	rows, err := q.QueryContext(ctx, query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	details := make([]models.TransactionDetail, 0)
+	for rows.Next() {
+		detail := models.TransactionDetail{TransactionID: transactionID}
+		var promotionID, taxClassID sql.NullInt64
+		err := rows.Scan(&detail.ID, &detail.ProductID, &detail.ProductName, &detail.Quantity, &detail.UnitPrice,
+			&detail.GrossAmount, &detail.DiscountAmount, &promotionID, &detail.PromotionName, &taxClassID,
+			&detail.TaxRate, &detail.TaxAmount, &detail.Subtotal, &detail.RefundedQuantity, &detail.RefundedAmount,
+			&detail.RefundedTaxAmount)
+		if err != nil {
+			return nil, err
+		}
+		if promotionID.Valid {
+			id := int(promotionID.Int64)
+			detail.PromotionID = &id
+		}
+		if taxClassID.Valid {
+			id := int(taxClassID.Int64)
+			detail.TaxClassID = &id
+		}
+		details = append(details, detail)
+	}
+
+	return details, rows.Err()
+}
+
+func loadPayments(ctx context.Context, q querier, transactionID int) ([]models.Payment, error) {
+	rows, err := q.QueryContext(ctx, "SELECT id, method, amount, reference FROM transaction_payments WHERE transaction_id = $1 ORDER BY id", transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := make([]models.Payment, 0)
+	for rows.Next() {
+		payment := models.Payment{TransactionID: transactionID}
+		if err := rows.Scan(&payment.ID, &payment.Method, &payment.Amount, &payment.Reference); err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+const transactionSelect = `SELECT id, location_id, gross_amount, discount_amount, tax_mode, tax_amount, total_amount,
+		paid_amount, change_amount, created_at
+	FROM transactions`
+
+func scanTransaction(row interface{ Scan(...any) error }, transaction *models.Transaction) error {
+	return row.Scan(&transaction.ID, &transaction.LocationID, &transaction.GrossAmount, &transaction.DiscountAmount,
+		&transaction.TaxMode, &transaction.TaxAmount, &transaction.TotalAmount, &transaction.PaidAmount,
+		&transaction.ChangeAmount, &transaction.CreatedAt)
+}
+
+func (repo *TransactionRepo) GetAll(ctx context.Context, filter models.TransactionFilter) ([]models.Transaction, int, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.StartDate != "" {
+		from, err := repo.dayStart(filter.StartDate)
+		if err != nil {
+			return nil, 0, err
+		}
+		args = append(args, from)
+		conditions = append(conditions, fmt.Sprintf("t.created_at >= $%d", len(args)))
+	}
+	if filter.EndDate != "" {
+		to, err := repo.dayStart(filter.EndDate)
+		if err != nil {
+			return nil, 0, err
+		}
+		args = append(args, to.AddDate(0, 0, 1))
+		conditions = append(conditions, fmt.Sprintf("t.created_at < $%d", len(args)))
+	}
+	if filter.MinAmount != 0 {
+		args = append(args, filter.MinAmount)
+		conditions = append(conditions, fmt.Sprintf("t.total_amount >= $%d", len(args)))
+	}
+	if filter.MaxAmount != 0 {
+		args = append(args, filter.MaxAmount)
+		conditions = append(conditions, fmt.Sprintf("t.total_amount <= $%d", len(args)))
+	}
+	if filter.ProductID != 0 {
+		args = append(args, filter.ProductID)
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM transaction_details td WHERE td.transaction_id = t.id AND td.product_id = $%d)", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM transactions t"+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query := fmt.Sprintf(`SELECT t.id, t.location_id, t.gross_amount, t.discount_amount, t.tax_mode, t.tax_amount,
+			t.total_amount, t.paid_amount, t.change_amount, t.created_at
+		FROM transactions t%s
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	transactions := make([]models.Transaction, 0)
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := scanTransaction(rows, &transaction); err != nil {
+			return nil, 0, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, total, rows.Err()
+}
+
+func (repo *TransactionRepo) GetByID(ctx context.Context, id int) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := scanTransaction(repo.db.QueryRowContext(ctx, transactionSelect+" WHERE id = $1", id), &transaction)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NotFound("transaction_not_found", "Transaction not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.Details, err = loadDetails(ctx, repo.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.Payments, err = loadPayments(ctx, repo.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// Postgres error codes, serialization failures and deadlocks mean the checkout
+// lost a race and can safely be retried
+const (
+	pgUniqueViolation      = "23505"
+	pgForeignKeyViolation  = "23503"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+const maxCheckoutAttempts = 3
+
+func (repo *TransactionRepo) CreateTransaction(ctx context.Context, request models.CheckoutRequest, actor string, settle SettleFunc) (*models.Transaction, error) {
+	var err error
+	for attempt := 1; attempt <= maxCheckoutAttempts; attempt++ {
+		var transaction *models.Transaction
+		transaction, err = repo.createTransaction(ctx, request, actor, settle)
+		if err == nil || !isRetryable(err) {
+			return transaction, err
+		}
+	}
+
+	return nil, err
 }
 
-func NewTransactionRepo(db *sql.DB) *TransactionRepo {
-	return &TransactionRepo{db: db}
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+	}
+
+	return false
 }
 
-func (repo *TransactionRepo) CreateTransaction(items []models.CheckoutItem) (*models.Transaction, error) {
-	dbTransaction, err := repo.db.Begin()
+func (repo *TransactionRepo) createTransaction(ctx context.Context, request models.CheckoutRequest, actor string, settle SettleFunc) (*models.Transaction, error) {
+	items := request.Items
+
+	dbTransaction, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer dbTransaction.Rollback()
 
-	// Build WHERE IN query to fetch all products at once
-	paramPlaceholder := make([]string, len(items))
-	args := make([]interface{}, len(items))
-	for i, item := range items {
+	// Sum quantities per product so repeated lines are checked against the stock together
+	requested := make(map[int]int)
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		if _, exists := requested[item.ProductID]; !exists {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+	if len(productIDs) == 0 {
+		return nil, apperrors.Validation("empty_checkout", "checkout has no items")
+	}
+
+	// Build WHERE IN query to fetch and lock all products at once.
+	// Rows are locked in id order so concurrent checkouts can't deadlock each other.
+	paramPlaceholder := make([]string, len(productIDs))
+	args := make([]interface{}, len(productIDs))
+	for i, productID := range productIDs {
 		paramPlaceholder[i] = fmt.Sprintf("$%d", i+1) // results in $1, $2, ...
-		args[i] = item.ProductID
+		args[i] = productID
 	}
 
-	query := fmt.Sprintf("SELECT id, name, price, stock FROM products WHERE id IN (%s)", strings.Join(paramPlaceholder, ", "))
-	rows, err := dbTransaction.Query(query, args...)
+	// Stock is read at the checkout's location, bound as the last parameter
+	args = append(args, request.LocationID)
+	// Lines of products with a tax class carry its rate, settle applies the default to the rest
+	query := fmt.Sprintf(`SELECT p.id, p.name, p.price, COALESCE(ps.quantity, 0), p.category_id, p.tax_class_id,
+			COALESCE(tc.rate, 0)
+		FROM products p
+		LEFT JOIN product_stocks ps ON ps.product_id = p.id AND ps.location_id = $%d
+		LEFT JOIN tax_classes tc ON tc.id = p.tax_class_id
+		WHERE p.id IN (%s)
+		ORDER BY p.id
+		FOR UPDATE OF p`, len(args), strings.Join(paramPlaceholder, ", "))
+	rows, err := dbTransaction.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
 	// Map products by ID for quick lookup
 	productMap := make(map[int]models.Product)
+	taxRates := make(map[int]int)
 	for rows.Next() {
 		var productResult models.Product
-		if err := rows.Scan(&productResult.ID, &productResult.Name, &productResult.Price, &productResult.Stock); err != nil {
+		var categoryID, taxClassID sql.NullInt64
+		var taxRate int
+		err := rows.Scan(&productResult.ID, &productResult.Name, &productResult.Price, &productResult.Stock,
+			&categoryID, &taxClassID, &taxRate)
+		if err != nil {
+			rows.Close()
 			return nil, err
 		}
+		if categoryID.Valid {
+			id := int(categoryID.Int64)
+			productResult.CategoryID = &id
+		}
+		if taxClassID.Valid {
+			id := int(taxClassID.Int64)
+			productResult.TaxClassID = &id
+		}
 
 		productMap[productResult.ID] = productResult
+		taxRates[productResult.ID] = taxRate
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	// Validate all products exist and build details
-	totalAmount := 0
-	details := make([]models.TransactionDetail, 0)
-
-	for _, item := range items {
-		product, exists := productMap[item.ProductID]
+	// Validate all products exist and have enough stock before touching anything
+	for _, productID := range productIDs {
+		product, exists := productMap[productID]
 		if !exists {
-			return nil, fmt.Errorf("product id %d not found", item.ProductID)
+			return nil, apperrors.NotFound("product_not_found", "product id %d not found", productID)
 		}
 
-		if product.Stock < item.Quantity {
-			return nil, fmt.Errorf("product id %d is out of stock", item.ProductID)
+		if product.Stock < requested[productID] {
+			return nil, apperrors.InsufficientStock("out_of_stock", "product id %d is out of stock", productID)
 		}
+	}
 
-		subtotal := product.Price * item.Quantity
-		totalAmount += subtotal
+	// Decrement stock, the stock guard protects against anything that slipped past the lock
+	movements := make([]models.StockMovement, 0, len(productIDs))
+	for _, productID := range productIDs {
+		movement := models.StockMovement{
+			ProductID:     productID,
+			LocationID:    request.LocationID,
+			Reason:        models.StockReasonSale,
+			QuantityDelta: -requested[productID],
+			Actor:         actor,
+		}
 
-		_, err = dbTransaction.Exec("UPDATE products SET stock = stock - $1 WHERE id = $2", item.Quantity, item.ProductID)
-		if err != nil {
+		if err := applyStock(ctx, dbTransaction, &movement); err != nil {
 			return nil, err
 		}
+		movements = append(movements, movement)
+	}
+
+	// Build details
+	transaction := models.Transaction{
+		LocationID: request.LocationID,
+		Details:    make([]models.TransactionDetail, 0, len(items)),
+		Payments:   request.Payments,
+	}
 
-		details = append(details, models.TransactionDetail{
+	for _, item := range items {
+		product := productMap[item.ProductID]
+
+		subtotal := product.Price * item.Quantity
+		transaction.GrossAmount += subtotal
+		transaction.TotalAmount += subtotal
+
+		transaction.Details = append(transaction.Details, models.TransactionDetail{
 			ProductID:   item.ProductID,
 			ProductName: product.Name,
 			Quantity:    item.Quantity,
+			UnitPrice:   product.Price,
+			GrossAmount: subtotal,
+			TaxClassID:  product.TaxClassID,
+			TaxRate:     taxRates[item.ProductID],
 			Subtotal:    subtotal,
+			CategoryID:  product.CategoryID,
 		})
 	}
 
-	var transactionID int
-	err = dbTransaction.QueryRow("INSERT INTO transactions (total_amount) VALUES ($1) RETURNING id", totalAmount).Scan(&transactionID)
+	// settle applies the promotions and tax and takes the payments
+	if err := settle(&transaction); err != nil {
+		return nil, err
+	}
+
+	err = dbTransaction.QueryRowContext(
+		ctx, `INSERT INTO transactions (location_id, gross_amount, discount_amount, tax_mode, tax_amount, total_amount,
+			paid_amount, change_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at`,
+		transaction.LocationID, transaction.GrossAmount, transaction.DiscountAmount, transaction.TaxMode,
+		transaction.TaxAmount, transaction.TotalAmount, transaction.PaidAmount, transaction.ChangeAmount,
+	).Scan(&transaction.ID, &transaction.CreatedAt)
 
 	if err != nil {
 		return nil, err
 	}
+	transactionID := transaction.ID
+	details := transaction.Details
+
+	for i := range movements {
+		movements[i].ReferenceID = &transactionID
+		if err := insertMovement(ctx, dbTransaction, &movements[i]); err != nil {
+			return nil, err
+		}
+	}
 
 	// Set TransactionID and build batch insert
+	const detailColumns = 11
 	insertParamPlaceHolder := make([]string, len(details))
-	insertArgs := make([]interface{}, 0, len(details)*4)
+	insertArgs := make([]interface{}, 0, len(details)*detailColumns)
 
 	for i := range details {
 		details[i].TransactionID = transactionID
-		insertParamPlaceHolder[i] = fmt.Sprintf("($%d, $%d, $%d, $%d)", i*4+1, i*4+2, i*4+3, i*4+4)
-		insertArgs = append(insertArgs, transactionID, details[i].ProductID, details[i].Quantity, details[i].Subtotal)
+
+		params := make([]string, detailColumns)
+		for j := range params {
+			params[j] = fmt.Sprintf("$%d", i*detailColumns+j+1)
+		}
+		insertParamPlaceHolder[i] = "(" + strings.Join(params, ", ") + ")"
+		insertArgs = append(insertArgs, transactionID, details[i].ProductID, details[i].Quantity, details[i].UnitPrice,
+			details[i].GrossAmount, details[i].DiscountAmount, details[i].PromotionID, details[i].TaxClassID,
+			details[i].TaxRate, details[i].TaxAmount, details[i].Subtotal)
 	}
 
-	// Batch insert query
-	_, err = dbTransaction.Exec(
-		fmt.Sprintf("INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES %s", strings.Join(insertParamPlaceHolder, ", ")),
+	// Batch insert query, returning the ids in insert order
+	rows, err = dbTransaction.QueryContext(
+		ctx, fmt.Sprintf(`INSERT INTO transaction_details
+			(transaction_id, product_id, quantity, unit_price, gross_amount, discount_amount, promotion_id,
+				tax_class_id, tax_rate, tax_amount, subtotal)
+			VALUES %s RETURNING id`, strings.Join(insertParamPlaceHolder, ", ")),
 		insertArgs...,
 	)
 	if err != nil {
 		return nil, err
 	}
+	for i := 0; rows.Next(); i++ {
+		if err := rows.Scan(&details[i].ID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	// Loop the details and insert to db
-	// for _, detail := range details {
-	// 	detail.TransactionID = transactionID
-	// 	_, err = dbTransaction.Exec("INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES ($1, $2, $3, $4)",
-	// 		transactionID, detail.ProductID, detail.Quantity, detail.Subtotal)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// }
+	// Record the payments that settled the transaction
+	if err := insertPayments(ctx, dbTransaction, transaction.ID, transaction.Payments); err != nil {
+		return nil, err
+	}
 
 	if err := dbTransaction.Commit(); err != nil {
 		return nil, err
 	}
 
-	return &models.Transaction{
-		ID:          transactionID,
-		TotalAmount: totalAmount,
-		Details:     details,
-	}, nil
+	return &transaction, nil
+}
+
+func insertPayments(ctx context.Context, dbTransaction *sql.Tx, transactionID int, payments []models.Payment) error {
+	if len(payments) == 0 {
+		return nil
+	}
+
+	placeHolder := make([]string, len(payments))
+	args := make([]interface{}, 0, len(payments)*4)
+
+	for i := range payments {
+		payments[i].TransactionID = transactionID
+		placeHolder[i] = fmt.Sprintf("($%d, $%d, $%d, $%d)", i*4+1, i*4+2, i*4+3, i*4+4)
+		args = append(args, transactionID, payments[i].Method, payments[i].Amount, payments[i].Reference)
+	}
+
+	rows, err := dbTransaction.QueryContext(
+		ctx, fmt.Sprintf("INSERT INTO transaction_payments (transaction_id, method, amount, reference) VALUES %s RETURNING id", strings.Join(placeHolder, ", ")),
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for i := 0; rows.Next(); i++ {
+		if err := rows.Scan(&payments[i].ID); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
 
-func (repo *TransactionRepo) Report(startDate string, endDate string) (*models.ReportResponse, error) {
-	return &models.ReportResponse{}, nil
+func (repo *TransactionRepo) CreateRefund(ctx context.Context, transactionID int, request models.RefundRequest, actor string, plan RefundFunc) (*models.Refund, error) {
+	dbTransaction, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dbTransaction.Rollback()
+
+	// Lock the transaction so concurrent refunds against it are applied one at a time
+	transaction := models.Transaction{}
+	err = scanTransaction(dbTransaction.QueryRowContext(ctx, transactionSelect+" WHERE id = $1 FOR UPDATE", transactionID), &transaction)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NotFound("transaction_not_found", "Transaction not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.Details, err = loadDetails(ctx, dbTransaction, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := models.Refund{
+		TransactionID: transactionID,
+		Reason:        request.Reason,
+		Restock:       request.Restock,
+	}
+	if err := plan(&transaction, &refund); err != nil {
+		return nil, err
+	}
+
+	err = dbTransaction.QueryRowContext(
+		ctx, "INSERT INTO refunds (transaction_id, amount, reason, restock) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
+		refund.TransactionID, refund.Amount, refund.Reason, refund.Restock,
+	).Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range refund.Items {
+		item := &refund.Items[i]
+		item.RefundID = refund.ID
+
+		err = dbTransaction.QueryRowContext(
+			ctx, `INSERT INTO refund_items (refund_id, transaction_detail_id, quantity, amount, tax_amount)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+			item.RefundID, item.TransactionDetailID, item.Quantity, item.Amount, item.TaxAmount,
+		).Scan(&item.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		// restocked items go back to the location they were sold from
+		if refund.Restock {
+			movement := models.StockMovement{
+				ProductID:     item.ProductID,
+				LocationID:    transaction.LocationID,
+				Reason:        models.StockReasonRefund,
+				QuantityDelta: item.Quantity,
+				Actor:         actor,
+				ReferenceID:   &refund.ID,
+			}
+
+			if err := applyStock(ctx, dbTransaction, &movement); err != nil {
+				return nil, err
+			}
+
+			if err := insertMovement(ctx, dbTransaction, &movement); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := dbTransaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+func (repo *TransactionRepo) Report(ctx context.Context, startDate string, endDate string) (*models.ReportResponse, error) {
+	from, to, err := repo.days(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	report := models.ReportResponse{}
+
+	var totalChange int
+	query := `SELECT COALESCE(SUM(gross_amount), 0), COALESCE(SUM(discount_amount), 0), COALESCE(SUM(total_amount), 0),
+			COALESCE(SUM(tax_amount), 0), COUNT(*), COALESCE(SUM(change_amount), 0)
+		FROM transactions
+		WHERE created_at >= $1 AND created_at < $2`
+	err = repo.db.QueryRowContext(ctx, query, from, to).Scan(&report.GrossSales, &report.TotalDiscount, &report.NetSales,
+		&report.TotalTax, &report.TotalTransaction, &totalChange)
+	if err != nil {
+		return nil, err
+	}
+	report.TotalRevenue = report.NetSales
+
+	// Refunds count against the period they were given in
+	query = "SELECT COALESCE(SUM(amount), 0) FROM refunds WHERE created_at >= $1 AND created_at < $2"
+	err = repo.db.QueryRowContext(ctx, query, from, to).Scan(&report.TotalRefund)
+	if err != nil {
+		return nil, err
+	}
+	report.TotalRevenue -= report.TotalRefund
+
+	// Tendered amounts per method, change is handed back from the cash drawer
+	query = `SELECT p.method, SUM(p.amount)
+		FROM transaction_payments p
+		JOIN transactions t ON t.id = p.transaction_id
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		GROUP BY p.method
+		ORDER BY p.method`
+	rows, err := repo.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report.RevenueByPaymentMethod = make([]models.PaymentMethodRevenue, 0)
+	for rows.Next() {
+		var revenue models.PaymentMethodRevenue
+		if err := rows.Scan(&revenue.Method, &revenue.Amount); err != nil {
+			return nil, err
+		}
+		if revenue.Method == models.PaymentMethodCash {
+			revenue.Amount -= totalChange
+		}
+		report.RevenueByPaymentMethod = append(report.RevenueByPaymentMethod, revenue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Best seller is the product with the highest quantity sold in the range and not
+	// refunded since
+	query = `SELECT p.name, SUM(td.quantity - COALESCE(ri.quantity, 0)) AS sold_qty
+		FROM transaction_details td
+		JOIN transactions t ON t.id = td.transaction_id
+		JOIN products p ON p.id = td.product_id
+		LEFT JOIN (
+			SELECT transaction_detail_id, SUM(quantity) AS quantity
+			FROM refund_items
+			GROUP BY transaction_detail_id
+		) ri ON ri.transaction_detail_id = td.id
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		GROUP BY p.id, p.name
+		HAVING SUM(td.quantity - COALESCE(ri.quantity, 0)) > 0
+		ORDER BY sold_qty DESC, p.name ASC
+		LIMIT 1`
+	err = repo.db.QueryRowContext(ctx, query, from, to).Scan(&report.BestSellerProduct.Name, &report.BestSellerProduct.SoldQty)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// TaxReport groups the tax on sales made in the period and on refunds given in it
+// by tax class and rate
+func (repo *TransactionRepo) TaxReport(ctx context.Context, startDate string, endDate string) (*models.TaxReport, error) {
+	from, to, err := repo.days(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	report := models.TaxReport{StartDate: startDate, EndDate: endDate, Lines: make([]models.TaxReportLine, 0)}
+
+	sales := `SELECT td.tax_class_id, COALESCE(tc.name, ''), td.tax_rate, SUM(td.subtotal - td.tax_amount), SUM(td.tax_amount)
+		FROM transaction_details td
+		JOIN transactions t ON t.id = td.transaction_id
+		LEFT JOIN tax_classes tc ON tc.id = td.tax_class_id
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		GROUP BY td.tax_class_id, tc.name, td.tax_rate`
+	err = repo.sumTax(ctx, sales, from, to, func(line *models.TaxReportLine, taxable int, tax int) {
+		line.TaxableAmount, line.TaxAmount = taxable, tax
+	}, &report)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds := `SELECT td.tax_class_id, COALESCE(tc.name, ''), td.tax_rate, SUM(ri.amount - ri.tax_amount), SUM(ri.tax_amount)
+		FROM refund_items ri
+		JOIN refunds r ON r.id = ri.refund_id
+		JOIN transaction_details td ON td.id = ri.transaction_detail_id
+		LEFT JOIN tax_classes tc ON tc.id = td.tax_class_id
+		WHERE r.created_at >= $1 AND r.created_at < $2
+		GROUP BY td.tax_class_id, tc.name, td.tax_rate`
+	err = repo.sumTax(ctx, refunds, from, to, func(line *models.TaxReportLine, taxable int, tax int) {
+		line.RefundedTaxableAmount, line.RefundedTaxAmount = taxable, tax
+	}, &report)
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// sumTax adds the rows of a tax_class_id, name, rate, taxable, tax query to the report
+func (repo *TransactionRepo) sumTax(ctx context.Context, query string, from time.Time, to time.Time, set func(line *models.TaxReportLine, taxable int, tax int), report *models.TaxReport) error {
+	rows, err := repo.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line models.TaxReportLine
+		var taxClassID sql.NullInt64
+		var taxable, tax int
+		if err := rows.Scan(&taxClassID, &line.TaxClassName, &line.Rate, &taxable, &tax); err != nil {
+			return err
+		}
+		if taxClassID.Valid {
+			id := int(taxClassID.Int64)
+			line.TaxClassID = &id
+		}
+		set(&line, taxable, tax)
+		report.Add(line)
+	}
+
+	return rows.Err()
 }
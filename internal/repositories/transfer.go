@@ -0,0 +1,234 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+)
+
+type TransferRepo struct {
+	db *sql.DB
+}
+
+func NewTransferRepo(db *sql.DB) *TransferRepo {
+	return &TransferRepo{db: db}
+}
+
+const transferSelect = `SELECT id, from_location_id, to_location_id, status, note, created_by, created_at, completed_at
+	FROM stock_transfers`
+
+func scanTransfer(row interface{ Scan(...any) error }, transfer *models.StockTransfer) error {
+	var completedAt sql.NullTime
+	err := row.Scan(&transfer.ID, &transfer.FromLocationID, &transfer.ToLocationID, &transfer.Status,
+		&transfer.Note, &transfer.CreatedBy, &transfer.CreatedAt, &completedAt)
+	if err != nil {
+		return err
+	}
+
+	transfer.CompletedAt = nil
+	if completedAt.Valid {
+		transfer.CompletedAt = &completedAt.Time
+	}
+
+	return nil
+}
+
+func loadTransferItems(ctx context.Context, q querier, transferID int) ([]models.StockTransferItem, error) {
+	query := `SELECT ti.id, ti.product_id, p.name, ti.quantity
+		FROM stock_transfer_items ti
+		JOIN products p ON p.id = ti.product_id
+		WHERE ti.transfer_id = $1
+		ORDER BY ti.id`
+	rows, err := q.QueryContext(ctx, query, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]models.StockTransferItem, 0)
+	for rows.Next() {
+		item := models.StockTransferItem{TransferID: transferID}
+		if err := rows.Scan(&item.ID, &item.ProductID, &item.ProductName, &item.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (repo *TransferRepo) GetAll(ctx context.Context, status string) ([]models.StockTransfer, error) {
+	query := transferSelect
+	var args []interface{}
+	if status != "" {
+		args = append(args, status)
+		query += " WHERE status = $1"
+	}
+	query += " ORDER BY id DESC"
+
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := make([]models.StockTransfer, 0)
+	for rows.Next() {
+		var transfer models.StockTransfer
+		if err := scanTransfer(rows, &transfer); err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, rows.Err()
+}
+
+func (repo *TransferRepo) GetByID(ctx context.Context, id int) (*models.StockTransfer, error) {
+	var transfer models.StockTransfer
+	err := scanTransfer(repo.db.QueryRowContext(ctx, transferSelect+" WHERE id = $1", id), &transfer)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NotFound("transfer_not_found", "Transfer not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	transfer.Items, err = loadTransferItems(ctx, repo.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+func (repo *TransferRepo) Create(ctx context.Context, transfer *models.StockTransfer) error {
+	dbTransaction, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.Rollback()
+
+	productIDs := make([]int, 0, len(transfer.Items))
+	for _, item := range transfer.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	names, err := lockProducts(ctx, dbTransaction, productIDs)
+	if err != nil {
+		return err
+	}
+
+	transfer.Status = models.TransferStatusInTransit
+	err = dbTransaction.QueryRowContext(
+		ctx, `INSERT INTO stock_transfers (from_location_id, to_location_id, status, note, created_by)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		transfer.FromLocationID, transfer.ToLocationID, transfer.Status, transfer.Note, transfer.CreatedBy,
+	).Scan(&transfer.ID, &transfer.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range transfer.Items {
+		item := &transfer.Items[i]
+		item.TransferID = transfer.ID
+		item.ProductName = names[item.ProductID]
+
+		err = dbTransaction.QueryRowContext(
+			ctx, "INSERT INTO stock_transfer_items (transfer_id, product_id, quantity) VALUES ($1, $2, $3) RETURNING id",
+			item.TransferID, item.ProductID, item.Quantity,
+		).Scan(&item.ID)
+		if err != nil {
+			return err
+		}
+
+		movement := models.StockMovement{
+			ProductID:     item.ProductID,
+			LocationID:    transfer.FromLocationID,
+			Reason:        models.StockReasonTransferOut,
+			QuantityDelta: -item.Quantity,
+			Actor:         transfer.CreatedBy,
+			ReferenceID:   &transfer.ID,
+		}
+		if err := applyStock(ctx, dbTransaction, &movement); err != nil {
+			return err
+		}
+		if err := insertMovement(ctx, dbTransaction, &movement); err != nil {
+			return err
+		}
+	}
+
+	return dbTransaction.Commit()
+}
+
+func (repo *TransferRepo) Complete(ctx context.Context, id int, status string, actor string) (*models.StockTransfer, error) {
+	dbTransaction, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dbTransaction.Rollback()
+
+	var transfer models.StockTransfer
+	err = scanTransfer(dbTransaction.QueryRowContext(ctx, transferSelect+" WHERE id = $1 FOR UPDATE", id), &transfer)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NotFound("transfer_not_found", "Transfer not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != models.TransferStatusInTransit {
+		return nil, apperrors.Conflict("transfer_completed", "Transfer is already %s", transfer.Status)
+	}
+
+	transfer.Items, err = loadTransferItems(ctx, dbTransaction, id)
+	if err != nil {
+		return nil, err
+	}
+	productIDs := make([]int, 0, len(transfer.Items))
+	for _, item := range transfer.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	if _, err := lockProducts(ctx, dbTransaction, productIDs); err != nil {
+		return nil, err
+	}
+
+	// received stock arrives at the destination, cancelled stock goes back to the source
+	locationID, note := transfer.ToLocationID, ""
+	if status == models.TransferStatusCancelled {
+		locationID, note = transfer.FromLocationID, "transfer cancelled"
+	}
+
+	for _, item := range transfer.Items {
+		movement := models.StockMovement{
+			ProductID:     item.ProductID,
+			LocationID:    locationID,
+			Reason:        models.StockReasonTransferIn,
+			QuantityDelta: item.Quantity,
+			Actor:         actor,
+			ReferenceID:   &transfer.ID,
+			Note:          note,
+		}
+		if err := applyStock(ctx, dbTransaction, &movement); err != nil {
+			return nil, err
+		}
+		if err := insertMovement(ctx, dbTransaction, &movement); err != nil {
+			return nil, err
+		}
+	}
+
+	var completedAt sql.NullTime
+	err = dbTransaction.QueryRowContext(
+		ctx, "UPDATE stock_transfers SET status = $1, completed_at = now() WHERE id = $2 RETURNING completed_at", status, id,
+	).Scan(&completedAt)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Status = status
+	transfer.CompletedAt = &completedAt.Time
+
+	if err := dbTransaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type UserRepo struct {
+	db *sql.DB
+}
+
+func NewUserRepo(db *sql.DB) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+func (repo *UserRepo) GetAll(ctx context.Context) ([]models.User, error) {
+	rows, err := repo.db.QueryContext(ctx, "SELECT id, username, password_hash, role, created_at FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (repo *UserRepo) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	query := "SELECT id, username, password_hash, role, created_at FROM users WHERE username = $1"
+
+	var user models.User
+	err := repo.db.QueryRowContext(ctx, query, username).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NotFound("user_not_found", "User not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (repo *UserRepo) Create(ctx context.Context, user *models.User) error {
+	query := "INSERT INTO users (username, password_hash, role) VALUES ($1, $2, $3) RETURNING id, created_at"
+	err := repo.db.QueryRowContext(ctx, query, user.Username, user.PasswordHash, user.Role).Scan(&user.ID, &user.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return apperrors.Conflict("username_taken", "Username already exists")
+	}
+
+	return err
+}
+
+func (repo *UserRepo) Count(ctx context.Context) (int, error) {
+	var count int
+	err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count)
+
+	return count, err
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"store-api-go/internal/models"
+	"store-api-go/internal/receipt"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const longName = "Café Susu Gula Aren Extra Large With Oat Milk"
+
+// checkoutForReceipt sells 2 of a product too long for a receipt line and 1 that needs
+// escaping in html, with 11% tax on top
+func checkoutForReceipt(t *testing.T, api *testAPI) (models.Transaction, string) {
+	t.Helper()
+
+	cashier := api.token(t, models.RoleCashier)
+	manager := api.token(t, models.RoleManager)
+
+	items := make([]string, 0, 2)
+	for _, product := range []struct {
+		name     string
+		price    int
+		quantity int
+	}{{longName, 1500, 2}, {"Tea <Large>", 1000, 1}} {
+		var created models.Product
+		w := api.do(http.MethodPost, "/api/products", manager,
+			`{"name":"`+product.name+`","price":`+strconv.Itoa(product.price)+`,"stock":10}`)
+		if w.Code != http.StatusOK && w.Code != http.StatusCreated {
+			t.Fatalf("create product got %d %s", w.Code, w.Body.String())
+		}
+		decode(t, w, &created)
+		items = append(items, `{"product_id":`+strconv.Itoa(created.ID)+`,"quantity":`+strconv.Itoa(product.quantity)+`}`)
+	}
+
+	var transaction models.Transaction
+	w := api.do(http.MethodPost, "/api/checkout", cashier,
+		`{"items":[`+strings.Join(items, ",")+`],"payments":[{"method":"cash","amount":5000}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("checkout got %d %s", w.Code, w.Body.String())
+	}
+	decode(t, w, &transaction)
+
+	return transaction, cashier
+}
+
+func center(s string) string {
+	return strings.Repeat(" ", (receipt.Width-utf8.RuneCountInString(s))/2) + s
+}
+
+func columns(left string, right string) string {
+	return left + strings.Repeat(" ", receipt.Width-utf8.RuneCountInString(left)-len(right)) + right
+}
+
+func TestTextReceipt(t *testing.T) {
+	api := newTestAPI(t)
+	transaction, cashier := checkoutForReceipt(t, api)
+
+	w := api.do(http.MethodGet, "/api/transactions/"+strconv.Itoa(transaction.ID)+"/receipt", cashier, "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Fatalf("got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	rule := strings.Repeat("-", receipt.Width)
+	want := []string{
+		center("Toko Maju"),
+		center("Jl. Merdeka 1"),
+		center("Bandung"),
+		rule,
+		columns("No. "+strconv.Itoa(transaction.ID), transaction.CreatedAt.In(testLocation).Format("2006-01-02 15:04")),
+		rule,
+		string([]rune(longName)[:receipt.Width]),
+		columns("  2 x 1.500", "3.000"),
+		"Tea <Large>",
+		columns("  1 x 1.000", "1.000"),
+		rule,
+		columns("Subtotal", "4.000"),
+		columns("Tax", "440"),
+		columns("TOTAL", "4.440"),
+		rule,
+		columns("CASH", "5.000"),
+		columns("Change", "560"),
+		rule,
+		center("Thank you"),
+	}
+
+	got := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(got), len(want), w.Body.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d is %q, want %q", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestHTMLReceipt(t *testing.T) {
+	api := newTestAPI(t)
+	transaction, cashier := checkoutForReceipt(t, api)
+
+	w := api.do(http.MethodGet, "/api/transactions/"+strconv.Itoa(transaction.ID)+"/receipt?format=html", cashier, "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Fatalf("got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		"<h1>Toko Maju</h1>",
+		"<div>Jl. Merdeka 1</div>",
+		"<div>Bandung</div>",
+		"<td colspan=\"2\">" + longName + "</td>",
+		"Tea &lt;Large&gt;",
+		`<td>Tax</td><td class="amount">440</td>`,
+		`<td>TOTAL</td><td class="amount">4.440</td>`,
+		"<div>Thank you</div>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("receipt is missing %q", want)
+		}
+	}
+	if strings.Contains(body, "<Large>") {
+		t.Error("product name is not escaped")
+	}
+}
+
+func TestESCPOSReceipt(t *testing.T) {
+	api := newTestAPI(t)
+	transaction, cashier := checkoutForReceipt(t, api)
+	path := "/api/transactions/" + strconv.Itoa(transaction.ID) + "/receipt"
+
+	w := api.do(http.MethodGet, path+"?format=escpos", cashier, "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/octet-stream" {
+		t.Fatalf("got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if disposition := w.Header().Get("Content-Disposition"); !strings.Contains(disposition, "receipt-"+strconv.Itoa(transaction.ID)+".bin") {
+		t.Errorf("Content-Disposition is %q", disposition)
+	}
+
+	body := w.Body.Bytes()
+	init, cut := []byte{0x1b, '@'}, []byte{0x1b, 'd', 4, 0x1d, 'V', 'B', 0}
+	if !bytes.HasPrefix(body, init) || !bytes.HasSuffix(body, cut) {
+		t.Fatalf("receipt doesn't start with ESC @ and end with the feed and cut: %q", body)
+	}
+
+	// the text receipt with everything outside printable ASCII as '?'
+	text := api.do(http.MethodGet, path, cashier, "").Body.String()
+	want := strings.ReplaceAll(text, "é", "?")
+	if !strings.Contains(want, "Caf? Susu") {
+		t.Fatalf("text receipt is missing the product:\n%s", text)
+	}
+	if got := string(body[len(init) : len(body)-len(cut)]); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestReceiptInvalidFormat(t *testing.T) {
+	api := newTestAPI(t)
+	transaction, cashier := checkoutForReceipt(t, api)
+
+	w := api.do(http.MethodGet, "/api/transactions/"+strconv.Itoa(transaction.ID)+"/receipt?format=pdf", cashier, "")
+	if response := decode(t, w, nil); w.Code != http.StatusBadRequest || response.Code != "invalid_format" {
+		t.Errorf("got %d %s, want 400 invalid_format", w.Code, response.Code)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"net/http"
+	"store-api-go/internal/httpx"
+	"strings"
+)
+
+// Middleware wraps a handler. Chains run in the order given, the first one outermost.
+type Middleware func(http.Handler) http.Handler
+
+func chain(handler http.Handler, middleware []Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return handler
+}
+
+// methods tried when a path matches a route but not for the request's method
+var methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// Router registers "METHOD /path/{param}" routes on a ServeMux. Groups share the
+// mux and stack their middleware on top of their parent's. Requests that match no
+// route get a JSON 404, or a JSON 405 when the path exists for other methods.
+type Router struct {
+	mux        *http.ServeMux
+	middleware []Middleware
+	handler    http.Handler
+}
+
+// NewRouter wraps every request, including unmatched ones, in the global middleware
+func NewRouter(global ...Middleware) *Router {
+	r := &Router{mux: http.NewServeMux()}
+	r.handler = chain(http.HandlerFunc(r.dispatch), global)
+
+	return r
+}
+
+// Group returns a router for routes that also run middleware
+func (r *Router) Group(middleware ...Middleware) *Router {
+	return &Router{
+		mux:        r.mux,
+		middleware: append(append([]Middleware{}, r.middleware...), middleware...),
+		handler:    r.handler,
+	}
+}
+
+func (r *Router) Handle(method string, path string, handler http.HandlerFunc) {
+	r.mux.Handle(method+" "+path, chain(handler, r.middleware))
+}
+
+func (r *Router) Get(path string, handler http.HandlerFunc) {
+	r.Handle(http.MethodGet, path, handler)
+}
+
+func (r *Router) Post(path string, handler http.HandlerFunc) {
+	r.Handle(http.MethodPost, path, handler)
+}
+
+func (r *Router) Put(path string, handler http.HandlerFunc) {
+	r.Handle(http.MethodPut, path, handler)
+}
+
+func (r *Router) Delete(path string, handler http.HandlerFunc) {
+	r.Handle(http.MethodDelete, path, handler)
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.handler.ServeHTTP(w, req)
+}
+
+func (r *Router) dispatch(w http.ResponseWriter, req *http.Request) {
+	if _, pattern := r.mux.Handler(req); pattern != "" {
+		r.mux.ServeHTTP(w, req)
+		return
+	}
+
+	allowed := make([]string, 0)
+	for _, method := range methods {
+		probe := req.Clone(req.Context())
+		probe.Method = method
+		if _, pattern := r.mux.Handler(probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+
+	if len(allowed) == 0 {
+		httpx.WriteFail(w, http.StatusNotFound, "route_not_found", "Not found")
+		return
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	httpx.WriteFail(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+}
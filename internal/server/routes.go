@@ -0,0 +1,143 @@
+package server
+
+import (
+	"net/http"
+	"store-api-go/internal/auth"
+	"store-api-go/internal/handlers"
+	"store-api-go/internal/idempotency"
+	"store-api-go/internal/metrics"
+	"store-api-go/internal/models"
+	"store-api-go/internal/tracing"
+)
+
+// Handlers is everything the API routes are served by
+type Handlers struct {
+	Tokens      *auth.TokenManager
+	Idempotency *idempotency.Keys
+	Health      *handlers.HealthHandler
+
+	Users          *handlers.UserHandler
+	Categories     *handlers.CategoryHandler
+	Products       *handlers.ProductHandler
+	Transactions   *handlers.TransactionHandler
+	Locations      *handlers.LocationHandler
+	Transfers      *handlers.TransferHandler
+	Suppliers      *handlers.SupplierHandler
+	PurchaseOrders *handlers.PurchaseOrderHandler
+	Promotions     *handlers.PromotionHandler
+	TaxClasses     *handlers.TaxClassHandler
+}
+
+// Minimum role per method, every /api route except login needs a token
+var (
+	catalogRoles = auth.MethodRoles{
+		http.MethodGet:    models.RoleCashier,
+		http.MethodPost:   models.RoleManager,
+		http.MethodPut:    models.RoleManager,
+		http.MethodDelete: models.RoleManager,
+	}
+	cashierRoles = auth.MethodRoles{
+		http.MethodGet:  models.RoleCashier,
+		http.MethodPost: models.RoleCashier,
+	}
+	managerRoles = auth.MethodRoles{
+		http.MethodGet:  models.RoleManager,
+		http.MethodPost: models.RoleManager,
+	}
+	adminRoles = auth.MethodRoles{
+		http.MethodGet:  models.RoleAdmin,
+		http.MethodPost: models.RoleAdmin,
+	}
+	// cashiers can look up receipts, refunds need a manager
+	transactionRoles = auth.MethodRoles{
+		http.MethodGet:  models.RoleCashier,
+		http.MethodPost: models.RoleManager,
+	}
+)
+
+// NewHandler builds the API's routes. allowedOrigins are the CORS origins, none
+// disables CORS.
+func NewHandler(h Handlers, allowedOrigins []string) http.Handler {
+	router := NewRouter(tracing.Middleware, RequestID, Logger, Recover, CORS(allowedOrigins), JSON, metrics.Middleware, tracing.Route)
+
+	router.Get("/healthz/live", h.Health.Live)
+	router.Get("/healthz/ready", h.Health.Ready)
+	router.Post("/api/auth/login", h.Users.Login)
+
+	admin := router.Group(h.Tokens.Middleware(adminRoles))
+	admin.Get("/api/users", h.Users.GetAll)
+	admin.Post("/api/users", h.Users.Create)
+
+	// retried POSTs with the same Idempotency-Key get the first response back
+	idempotent := h.Idempotency.Wrap
+
+	catalog := router.Group(h.Tokens.Middleware(catalogRoles))
+	catalog.Get("/api/categories", h.Categories.GetAll)
+	catalog.Post("/api/categories", idempotent(h.Categories.Create))
+	catalog.Get("/api/categories/{id}", h.Categories.GetByID)
+	catalog.Put("/api/categories/{id}", h.Categories.Update)
+	catalog.Delete("/api/categories/{id}", h.Categories.Delete)
+	catalog.Get("/api/categories/{id}/products", h.Categories.GetProducts)
+
+	catalog.Get("/api/products", h.Products.GetAll)
+	catalog.Post("/api/products", idempotent(h.Products.Create))
+	catalog.Get("/api/products/{id}", h.Products.GetByID)
+	catalog.Put("/api/products/{id}", h.Products.Update)
+	catalog.Delete("/api/products/{id}", h.Products.Delete)
+	catalog.Get("/api/products/{id}/stock-history", h.Products.GetStockHistory)
+	catalog.Post("/api/products/{id}/stock-adjustments", h.Products.AdjustStock)
+	catalog.Post("/api/products/{id}/stocktakes", h.Products.Stocktake)
+
+	catalog.Get("/api/locations", h.Locations.GetAll)
+	catalog.Post("/api/locations", h.Locations.Create)
+	catalog.Get("/api/locations/{id}", h.Locations.GetByID)
+	catalog.Put("/api/locations/{id}", h.Locations.Update)
+
+	catalog.Get("/api/transfers", h.Transfers.GetAll)
+	catalog.Post("/api/transfers", h.Transfers.Create)
+	catalog.Get("/api/transfers/{id}", h.Transfers.GetByID)
+	catalog.Post("/api/transfers/{id}/receive", h.Transfers.Receive)
+	catalog.Post("/api/transfers/{id}/cancel", h.Transfers.Cancel)
+
+	catalog.Get("/api/suppliers", h.Suppliers.GetAll)
+	catalog.Post("/api/suppliers", h.Suppliers.Create)
+	catalog.Get("/api/suppliers/{id}", h.Suppliers.GetByID)
+	catalog.Put("/api/suppliers/{id}", h.Suppliers.Update)
+	catalog.Delete("/api/suppliers/{id}", h.Suppliers.Delete)
+
+	catalog.Get("/api/purchase-orders", h.PurchaseOrders.GetAll)
+	catalog.Post("/api/purchase-orders", h.PurchaseOrders.Create)
+	catalog.Get("/api/purchase-orders/on-order", h.PurchaseOrders.OnOrder)
+	catalog.Get("/api/purchase-orders/{id}", h.PurchaseOrders.GetByID)
+	catalog.Post("/api/purchase-orders/{id}/send", h.PurchaseOrders.Send)
+	catalog.Post("/api/purchase-orders/{id}/close", h.PurchaseOrders.Close)
+	catalog.Post("/api/purchase-orders/{id}/receipts", h.PurchaseOrders.Receive)
+
+	catalog.Get("/api/promotions", h.Promotions.GetAll)
+	catalog.Post("/api/promotions", h.Promotions.Create)
+	catalog.Get("/api/promotions/{id}", h.Promotions.GetByID)
+	catalog.Put("/api/promotions/{id}", h.Promotions.Update)
+	catalog.Delete("/api/promotions/{id}", h.Promotions.Delete)
+
+	catalog.Get("/api/tax-classes", h.TaxClasses.GetAll)
+	catalog.Post("/api/tax-classes", h.TaxClasses.Create)
+	catalog.Get("/api/tax-classes/{id}", h.TaxClasses.GetByID)
+	catalog.Put("/api/tax-classes/{id}", h.TaxClasses.Update)
+	catalog.Delete("/api/tax-classes/{id}", h.TaxClasses.Delete)
+
+	cashier := router.Group(h.Tokens.Middleware(cashierRoles))
+	cashier.Post("/api/checkout", idempotent(h.Transactions.Checkout))
+	cashier.Get("/api/transactions", h.Transactions.GetAll)
+
+	transactions := router.Group(h.Tokens.Middleware(transactionRoles))
+	transactions.Get("/api/transactions/{id}", h.Transactions.GetByID)
+	transactions.Post("/api/transactions/{id}/refunds", h.Transactions.Refund)
+	transactions.Get("/api/transactions/{id}/receipt", h.Transactions.Receipt)
+
+	manager := router.Group(h.Tokens.Middleware(managerRoles))
+	manager.Get("/api/report", h.Transactions.Report)
+	manager.Get("/api/report/hari-ini", h.Transactions.ReportToday)
+	manager.Get("/api/report/tax", h.Transactions.TaxReport)
+
+	return router
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"store-api-go/internal/auth"
+	"store-api-go/internal/handlers"
+	"store-api-go/internal/idempotency"
+	"store-api-go/internal/models"
+	"store-api-go/internal/receipt"
+	"store-api-go/internal/repositories/memory"
+	"store-api-go/internal/services"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "test-secret"
+
+// east of UTC so dates printed in UTC or the machine's zone show up
+var testLocation = time.FixedZone("WIB", 7*60*60)
+
+// testAPI is the full API over the memory store, wired as main does
+type testAPI struct {
+	handler http.Handler
+	tokens  *auth.TokenManager
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+
+	store := memory.NewStore()
+	userRepo := memory.NewUserRepo(store)
+	categoryRepo := memory.NewCategoryRepo(store)
+	productRepo := memory.NewProductRepo(store)
+	locationRepo := memory.NewLocationRepo(store)
+	supplierRepo := memory.NewSupplierRepo(store)
+	promotionRepo := memory.NewPromotionRepo(store)
+	taxClassRepo := memory.NewTaxClassRepo(store)
+
+	tokens := auth.NewTokenManager(testSecret, time.Hour)
+	receipts, err := receipt.New(receipt.Store{
+		Name:    "Toko Maju",
+		Address: `Jl. Merdeka 1\nBandung`,
+		Footer:  "Thank you",
+	}, "", testLocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const locationID = 1
+	handler := NewHandler(Handlers{
+		Tokens:      tokens,
+		Idempotency: idempotency.New(memory.NewIdempotencyRepo(store), time.Hour, time.Minute),
+		Health:      handlers.NewHealthHandler(nil, nil, time.Second),
+
+		Users:      handlers.NewUserHandler(services.NewUserService(userRepo, tokens)),
+		Categories: handlers.NewCategoryHandler(services.NewCategoryService(categoryRepo, productRepo, services.DeletePolicyRestrict)),
+		Products: handlers.NewProductHandler(services.NewProductService(productRepo, categoryRepo, locationRepo,
+			taxClassRepo, locationID)),
+		Transactions: handlers.NewTransactionHandler(services.NewTransactionService(memory.NewTransactionRepo(store, testLocation),
+			locationRepo, promotionRepo, locationID, models.TaxExclusive, 1100, testLocation), receipts),
+		Locations: handlers.NewLocationHandler(services.NewLocationService(locationRepo)),
+		Transfers: handlers.NewTransferHandler(services.NewTransferService(memory.NewTransferRepo(store), locationRepo)),
+		Suppliers: handlers.NewSupplierHandler(services.NewSupplierService(supplierRepo)),
+		PurchaseOrders: handlers.NewPurchaseOrderHandler(services.NewPurchaseOrderService(memory.NewPurchaseOrderRepo(store),
+			supplierRepo, locationRepo, locationID)),
+		Promotions: handlers.NewPromotionHandler(services.NewPromotionService(promotionRepo, productRepo, categoryRepo)),
+		TaxClasses: handlers.NewTaxClassHandler(services.NewTaxClassService(taxClassRepo)),
+	}, nil)
+
+	return &testAPI{handler: handler, tokens: tokens}
+}
+
+// token issues a token for a user with role, the middleware only trusts the claims
+func (api *testAPI) token(t *testing.T, role string) string {
+	t.Helper()
+
+	token, _, err := api.tokens.Issue(&models.User{ID: 1, Username: role, Role: role})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func (api *testAPI) do(method string, path string, token string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	api.handler.ServeHTTP(w, r)
+
+	return w
+}
+
+// decode reads a JSON response, data is decoded into v when it isn't nil
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) models.Response {
+	t.Helper()
+
+	body, _ := io.ReadAll(w.Body)
+	var response struct {
+		models.Response
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("decode %s: %v", body, err)
+	}
+	if v != nil {
+		if err := json.Unmarshal(response.Data, v); err != nil {
+			t.Fatalf("decode data %s: %v", response.Data, err)
+		}
+	}
+
+	return response.Response
+}
+
+func TestCashierForbidden(t *testing.T) {
+	api := newTestAPI(t)
+	cashier := api.token(t, models.RoleCashier)
+	manager := api.token(t, models.RoleManager)
+
+	var product models.Product
+	w := api.do(http.MethodPost, "/api/products", manager, `{"name":"Coffee","price":1500,"stock":10}`)
+	if w.Code != http.StatusOK && w.Code != http.StatusCreated {
+		t.Fatalf("create product got %d %s", w.Code, w.Body.String())
+	}
+	decode(t, w, &product)
+
+	var transaction models.Transaction
+	w = api.do(http.MethodPost, "/api/checkout", cashier,
+		`{"items":[{"product_id":`+strconv.Itoa(product.ID)+`,"quantity":1}],"payments":[{"method":"cash","amount":5000}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("checkout got %d %s", w.Code, w.Body.String())
+	}
+	decode(t, w, &transaction)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/api/transactions/" + strconv.Itoa(transaction.ID) + "/refunds", `{}`},
+		{http.MethodPost, "/api/products", `{"name":"Tea","price":1000}`},
+		{http.MethodPut, "/api/products/" + strconv.Itoa(product.ID), `{"name":"Tea","price":1000}`},
+		{http.MethodDelete, "/api/products/" + strconv.Itoa(product.ID), ""},
+		{http.MethodPost, "/api/categories", `{"name":"Drinks"}`},
+		{http.MethodPost, "/api/promotions", `{"name":"Half off","type":"percentage","value":50}`},
+		{http.MethodPost, "/api/products/" + strconv.Itoa(product.ID) + "/stock-adjustments", `{"quantity":5,"reason":"found"}`},
+		{http.MethodGet, "/api/report", ""},
+		{http.MethodGet, "/api/users", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			w := api.do(test.method, test.path, cashier, test.body)
+			if response := decode(t, w, nil); w.Code != http.StatusForbidden || response.Code != "forbidden" {
+				t.Errorf("got %d %s, want 403 forbidden", w.Code, response.Code)
+			}
+		})
+	}
+
+	// nothing was changed by the refused requests
+	w = api.do(http.MethodGet, "/api/products/"+strconv.Itoa(product.ID), cashier, "")
+	decode(t, w, &product)
+	if product.Name != "Coffee" || product.Stock != 9 {
+		t.Errorf("product is %s with stock %d, want Coffee with 9", product.Name, product.Stock)
+	}
+	w = api.do(http.MethodGet, "/api/transactions/"+strconv.Itoa(transaction.ID), cashier, "")
+	decode(t, w, &transaction)
+	if transaction.Details[0].RefundedQuantity != 0 {
+		t.Errorf("refunded %d, want nothing", transaction.Details[0].RefundedQuantity)
+	}
+}
+
+func TestInvalidToken(t *testing.T) {
+	api := newTestAPI(t)
+	valid := api.token(t, models.RoleCashier)
+
+	expired, _, err := auth.NewTokenManager(testSecret, -time.Minute).Issue(&models.User{ID: 1, Role: models.RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSecret, _, err := auth.NewTokenManager("other-secret", time.Hour).Issue(&models.User{ID: 1, Role: models.RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a payload claiming admin under the cashier's signature
+	parts := strings.Split(valid, ".")
+	admin, _, _ := auth.NewTokenManager(testSecret, time.Hour).Issue(&models.User{ID: 1, Role: models.RoleAdmin})
+	escalated := parts[0] + "." + strings.Split(admin, ".")[1] + "." + parts[2]
+
+	tests := []struct {
+		name  string
+		token string
+		code  string
+	}{
+		{"missing", "", "missing_token"},
+		{"expired", expired, "invalid_token"},
+		{"other secret", otherSecret, "invalid_token"},
+		{"tampered payload", escalated, "invalid_token"},
+		{"tampered signature", valid[:len(valid)-4] + "AAAA", "invalid_token"},
+		{"garbage", "not-a-jwt", "invalid_token"},
+		{"unsigned", "eyJhbGciOiJub25lIn0." + parts[1] + ".", "invalid_token"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := api.do(http.MethodGet, "/api/products", test.token, "")
+			if response := decode(t, w, nil); w.Code != http.StatusUnauthorized || response.Code != test.code {
+				t.Errorf("got %d %s, want 401 %s", w.Code, response.Code, test.code)
+			}
+			if w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("WWW-Authenticate is not set")
+			}
+		})
+	}
+
+	if w := api.do(http.MethodGet, "/api/products", valid, ""); w.Code != http.StatusOK {
+		t.Errorf("valid token got %d, want 200", w.Code)
+	}
+}
+
+// TestInvalidCursor checks a bad cursor is the client's fault rather than a server error
+func TestInvalidCursor(t *testing.T) {
+	api := newTestAPI(t)
+	cashier := api.token(t, models.RoleCashier)
+
+	for _, path := range []string{
+		"/api/products?cursor=garbage",
+		"/api/products?cursor=eyJzIjoiaWQiLCJ2IjpbMS41XX0",
+		"/api/products?sort=-price&cursor=eyJzIjoiaWQiLCJ2IjpbMV19",
+		"/api/categories?cursor=%21%21",
+	} {
+		w := api.do(http.MethodGet, path, cashier, "")
+		if response := decode(t, w, nil); w.Code != http.StatusBadRequest || response.Code != "invalid_cursor" {
+			t.Errorf("%s got %d %s, want 400 invalid_cursor", path, w.Code, response.Code)
+		}
+	}
+}
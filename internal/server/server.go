@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type Config struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// how long shutdown waits for in flight requests
+	ShutdownTimeout time.Duration
+	// how long the server keeps serving after the OnShutdown hooks ran, so load
+	// balancers see the failing readiness probe before connections are refused
+	ShutdownDelay time.Duration
+}
+
+type Server struct {
+	http            *http.Server
+	shutdownTimeout time.Duration
+	shutdownDelay   time.Duration
+	onShutdown      []func()
+}
+
+func New(config Config, handler http.Handler) *Server {
+	return &Server{
+		http: &http.Server{
+			Addr:              config.Addr,
+			Handler:           handler,
+			ReadTimeout:       config.ReadTimeout,
+			ReadHeaderTimeout: config.ReadHeaderTimeout,
+			WriteTimeout:      config.WriteTimeout,
+			IdleTimeout:       config.IdleTimeout,
+		},
+		shutdownTimeout: config.ShutdownTimeout,
+		shutdownDelay:   config.ShutdownDelay,
+	}
+}
+
+// OnShutdown registers f to run as soon as shutdown starts, while still serving
+func (s *Server) OnShutdown(f func()) {
+	s.onShutdown = append(s.onShutdown, f)
+}
+
+// Run serves until ctx is cancelled, then runs the OnShutdown hooks and keeps serving
+// for the shutdown delay. After that it stops accepting connections and waits up to
+// the shutdown timeout for in flight requests to finish. Requests still running after
+// that are cut off and an error is returned.
+func (s *Server) Run(ctx context.Context) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.http.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	for _, f := range s.onShutdown {
+		f()
+	}
+	if s.shutdownDelay > 0 {
+		slog.Info("Shutdown requested, still serving", "delay", s.shutdownDelay)
+		time.Sleep(s.shutdownDelay)
+	}
+
+	slog.Info("Shutting down, waiting for in flight requests", "timeout", s.shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		s.http.Close()
+		return err
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -1,34 +1,110 @@
 package services
 
 import (
+	"context"
 	"store-api-go/internal/models"
 	"store-api-go/internal/repositories"
+	"strings"
 )
 
+// What happens to products when their category is deleted
+const (
+	DeletePolicyRestrict = "restrict"
+	DeletePolicyCascade  = "cascade"
+	DeletePolicyDetach   = "detach"
+)
+
+func IsValidDeletePolicy(policy string) bool {
+	return policy == DeletePolicyRestrict || policy == DeletePolicyCascade || policy == DeletePolicyDetach
+}
+
 type CategoryService struct {
-	repo *repositories.CategoryRepo
+	repo         repositories.CategoryRepository
+	productRepo  repositories.ProductRepository
+	deletePolicy string
 }
 
-func NewCategoryService(repo *repositories.CategoryRepo) *CategoryService {
-	return &CategoryService{repo: repo}
+func NewCategoryService(repo repositories.CategoryRepository, productRepo repositories.ProductRepository, deletePolicy string) *CategoryService {
+	return &CategoryService{repo: repo, productRepo: productRepo, deletePolicy: deletePolicy}
 }
 
-func (s *CategoryService) GetAll(name string) ([]models.Category, error) {
-	return s.repo.GetAll(name)
+func (s *CategoryService) GetAll(ctx context.Context, filter models.CategoryFilter) ([]models.Category, *models.CursorMeta, error) {
+	ctx, span := tracer.Start(ctx, "CategoryService.GetAll")
+	defer span.End()
+
+	filter.Sort, filter.Limit = normalizePage(filter.Sort, filter.Limit)
+
+	categories, next, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return categories, &models.CursorMeta{Limit: filter.Limit, NextCursor: next, HasMore: next != ""}, nil
 }
 
-func (s *CategoryService) Create(data *models.Category) error {
-	return s.repo.Create(data)
+func (s *CategoryService) Create(ctx context.Context, data *models.Category) error {
+	ctx, span := tracer.Start(ctx, "CategoryService.Create")
+	defer span.End()
+
+	data.Name = strings.TrimSpace(data.Name)
+	if err := validateUnique(ctx, data, data.Name, 0, s.repo.NameExists); err != nil {
+		return err
+	}
+
+	return s.repo.Create(ctx, data)
 }
 
-func (s *CategoryService) GetByID(id int) (*models.Category, error) {
-	return s.repo.GetByID(id)
+func (s *CategoryService) GetByID(ctx context.Context, id int) (*models.Category, error) {
+	ctx, span := tracer.Start(ctx, "CategoryService.GetByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
 }
 
-func (s *CategoryService) Update(category *models.Category) error {
-	return s.repo.Update(category)
+func (s *CategoryService) GetProducts(ctx context.Context, id int, filter models.ProductFilter) ([]models.Product, *models.CursorMeta, error) {
+	ctx, span := tracer.Start(ctx, "CategoryService.GetProducts")
+	defer span.End()
+
+	_, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filter.CategoryID = id
+	filter.Sort, filter.Limit = normalizePage(filter.Sort, filter.Limit)
+
+	products, next, err := s.productRepo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return products, &models.CursorMeta{Limit: filter.Limit, NextCursor: next, HasMore: next != ""}, nil
 }
 
-func (s *CategoryService) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *CategoryService) Update(ctx context.Context, category *models.Category) error {
+	ctx, span := tracer.Start(ctx, "CategoryService.Update")
+	defer span.End()
+
+	category.Name = strings.TrimSpace(category.Name)
+	if err := validateUnique(ctx, category, category.Name, category.ID, s.repo.NameExists); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, category)
+}
+
+func (s *CategoryService) Delete(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "CategoryService.Delete")
+	defer span.End()
+
+	// promotions of the category always refuse the delete, and so do its products
+	// unless the policy takes them out of it
+	switch s.deletePolicy {
+	case DeletePolicyCascade:
+		return s.repo.DeleteWithProducts(ctx, id)
+	case DeletePolicyDetach:
+		return s.repo.DeleteDetachingProducts(ctx, id)
+	default:
+		return s.repo.Delete(ctx, id)
+	}
 }
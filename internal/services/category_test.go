@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories/memory"
+	"testing"
+)
+
+// newCategoryWithProduct creates a category holding one product
+func newCategoryWithProduct(t *testing.T, store *memory.Store, deletePolicy string) (*CategoryService, *ProductService, *models.Category, *models.Product) {
+	t.Helper()
+
+	ctx := context.Background()
+	categories := NewCategoryService(memory.NewCategoryRepo(store), memory.NewProductRepo(store), deletePolicy)
+	products, _ := newTestServices(store)
+
+	category := models.Category{Name: "Drinks"}
+	if err := categories.Create(ctx, &category); err != nil {
+		t.Fatal(err)
+	}
+	product := models.Product{Name: "Coffee", Price: 1500, Stock: 1, CategoryID: &category.ID}
+	if err := products.Create(ctx, &product, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	return categories, products, &category, &product
+}
+
+func TestDeleteCategoryRestrict(t *testing.T) {
+	ctx := context.Background()
+	categories, products, category, product := newCategoryWithProduct(t, memory.NewStore(), DeletePolicyRestrict)
+
+	err := categories.Delete(ctx, category.ID)
+	if appErr := apperrors.As(err); appErr == nil || appErr.Code != "category_in_use" {
+		t.Fatalf("got %v, want category_in_use", err)
+	}
+	if _, err := categories.GetByID(ctx, category.ID); err != nil {
+		t.Errorf("category is gone after a refused delete: %v", err)
+	}
+
+	if err := products.Delete(ctx, product.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := categories.Delete(ctx, category.ID); err != nil {
+		t.Errorf("deleting the emptied category failed: %v", err)
+	}
+}
+
+func TestDeleteCategoryDetach(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	categories, products, category, product := newCategoryWithProduct(t, store, DeletePolicyDetach)
+
+	// products with sales are kept too
+	_, transactions := newTestServices(store)
+	_, err := transactions.Checkout(ctx, models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: product.ID, Quantity: 1}},
+		Payments: cash(product.Price),
+	}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := categories.Delete(ctx, category.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := categories.GetByID(ctx, category.ID); !isNotFound(err, "category_not_found") {
+		t.Errorf("got %v, want category_not_found", err)
+	}
+
+	kept, err := products.GetByID(ctx, product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept.CategoryID != nil || kept.CategoryName != "" {
+		t.Errorf("product still in category %v %q", kept.CategoryID, kept.CategoryName)
+	}
+}
+
+func TestDeleteCategoryCascade(t *testing.T) {
+	ctx := context.Background()
+	categories, products, category, product := newCategoryWithProduct(t, memory.NewStore(), DeletePolicyCascade)
+
+	if err := categories.Delete(ctx, category.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := categories.GetByID(ctx, category.ID); !isNotFound(err, "category_not_found") {
+		t.Errorf("got %v, want category_not_found", err)
+	}
+	if _, err := products.GetByID(ctx, product.ID); !isNotFound(err, "product_not_found") {
+		t.Errorf("got %v, want product_not_found", err)
+	}
+}
+
+func TestDeleteCategoryCascadeKeepsSoldProducts(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	categories, products, category, product := newCategoryWithProduct(t, store, DeletePolicyCascade)
+
+	_, transactions := newTestServices(store)
+	_, err := transactions.Checkout(ctx, models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: product.ID, Quantity: 1}},
+		Payments: cash(product.Price),
+	}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = categories.Delete(ctx, category.ID)
+	if appErr := apperrors.As(err); appErr == nil || appErr.Code != "product_in_use" {
+		t.Fatalf("got %v, want product_in_use", err)
+	}
+	if _, err := categories.GetByID(ctx, category.ID); err != nil {
+		t.Errorf("category is gone after a refused delete: %v", err)
+	}
+	if _, err := products.GetByID(ctx, product.ID); err != nil {
+		t.Errorf("product is gone after a refused delete: %v", err)
+	}
+}
+
+// TestDeleteCategoryWithPromotions checks no policy drops the promotions of a category,
+// sales would lose the promotion they were sold under
+func TestDeleteCategoryWithPromotions(t *testing.T) {
+	for _, policy := range []string{DeletePolicyRestrict, DeletePolicyCascade, DeletePolicyDetach} {
+		t.Run(policy, func(t *testing.T) {
+			ctx := context.Background()
+			store := memory.NewStore()
+			categories, products, category, product := newCategoryWithProduct(t, store, policy)
+			if err := products.Delete(ctx, product.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			promotionRepo := memory.NewPromotionRepo(store)
+			active := true
+			promotion := models.Promotion{Name: "Drinks 10% off", Type: models.PromotionPercentage, Value: 10, CategoryID: &category.ID, Active: &active}
+			if err := promotionRepo.Create(ctx, &promotion); err != nil {
+				t.Fatal(err)
+			}
+
+			err := categories.Delete(ctx, category.ID)
+			if appErr := apperrors.As(err); appErr == nil || appErr.Code != "category_has_promotions" {
+				t.Fatalf("got %v, want category_has_promotions", err)
+			}
+			if _, err := promotionRepo.GetByID(ctx, promotion.ID); err != nil {
+				t.Errorf("promotion is gone after a refused delete: %v", err)
+			}
+		})
+	}
+}
+
+func isNotFound(err error, code string) bool {
+	appErr := apperrors.As(err)
+	return appErr != nil && appErr.Kind == apperrors.KindNotFound && appErr.Code == code
+}
@@ -0,0 +1,104 @@
+//go:build integration
+
+// Run against a scratch database, the migrations are applied to it:
+//
+//	TEST_DB_CONN=postgres://... go test -tags integration ./internal/services/
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/database"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+	"sync"
+	"testing"
+	"time"
+)
+
+// openTestDB connects to TEST_DB_CONN and migrates it, skipping the test without it
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	conn := os.Getenv("TEST_DB_CONN")
+	if conn == "" {
+		t.Skip("TEST_DB_CONN is not set")
+	}
+
+	db, err := database.InitDB(conn, 20, 20, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// TestConcurrentCheckout races more buyers than there are units, the row locks must
+// let exactly stock of them through
+func TestConcurrentCheckout(t *testing.T) {
+	const stock, buyers = 5, 20
+
+	db := openTestDB(t)
+	ctx := context.Background()
+	locationRepo := repositories.NewLocationRepo(db)
+	products := NewProductService(repositories.NewProductRepo(db), repositories.NewCategoryRepo(db), locationRepo,
+		repositories.NewTaxClassRepo(db), 1)
+	transactions := NewTransactionService(repositories.NewTransactionRepo(db, time.UTC), locationRepo, repositories.NewPromotionRepo(db),
+		1, models.TaxInclusive, 0, time.UTC)
+
+	product := models.Product{Name: fmt.Sprintf("last units %d", time.Now().UnixNano()), Price: 1000, Stock: stock}
+	if err := products.Create(ctx, &product, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, buyers)
+	for range buyers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := transactions.Checkout(ctx, models.CheckoutRequest{
+				Items:    []models.CheckoutItem{{ProductID: product.ID, Quantity: 1}},
+				Payments: []models.Payment{{Method: models.PaymentMethodCash, Amount: product.Price}},
+			}, "test")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		if appErr := apperrors.As(err); !errors.Is(err, apperrors.ErrInsufficientStock) || appErr.Code != "out_of_stock" {
+			t.Errorf("checkout failed with %v, want out_of_stock", err)
+		}
+	}
+	if succeeded != stock {
+		t.Errorf("%d checkouts succeeded, want %d", succeeded, stock)
+	}
+
+	stored, err := products.GetByID(ctx, product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Stock != 0 {
+		t.Errorf("stock is %d after selling out, want 0", stored.Stock)
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+	"strings"
+)
+
+type LocationService struct {
+	repo repositories.LocationRepository
+}
+
+func NewLocationService(repo repositories.LocationRepository) *LocationService {
+	return &LocationService{repo: repo}
+}
+
+func (s *LocationService) GetAll(ctx context.Context) ([]models.Location, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.GetAll")
+	defer span.End()
+
+	return s.repo.GetAll(ctx)
+}
+
+func (s *LocationService) GetByID(ctx context.Context, id int) (*models.Location, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.GetByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *LocationService) Create(ctx context.Context, location *models.Location) error {
+	ctx, span := tracer.Start(ctx, "LocationService.Create")
+	defer span.End()
+
+	if err := normalizeLocation(location); err != nil {
+		return err
+	}
+
+	return s.repo.Create(ctx, location)
+}
+
+func (s *LocationService) Update(ctx context.Context, location *models.Location) error {
+	ctx, span := tracer.Start(ctx, "LocationService.Update")
+	defer span.End()
+
+	if err := normalizeLocation(location); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, location)
+}
+
+func normalizeLocation(location *models.Location) error {
+	location.Name = strings.TrimSpace(location.Name)
+	if location.Name == "" {
+		return apperrors.Validation("name_required", "name is required")
+	}
+
+	if location.Type == "" {
+		location.Type = models.LocationTypeStore
+	}
+	if location.Type != models.LocationTypeStore && location.Type != models.LocationTypeWarehouse {
+		return apperrors.Validation("invalid_location_type", "type must be store or warehouse")
+	}
+
+	return nil
+}
+
+// resolveLocation returns the location to use for a request, falling back to the
+// default location when none was given, and checks it exists
+func resolveLocation(ctx context.Context, repo repositories.LocationRepository, locationID int, defaultLocationID int) (int, error) {
+	if locationID == 0 {
+		locationID = defaultLocationID
+	}
+
+	if _, err := repo.GetByID(ctx, locationID); err != nil {
+		return 0, err
+	}
+
+	return locationID, nil
+}
@@ -0,0 +1,39 @@
+package services
+
+import "store-api-go/internal/pagination"
+
+// normalizePage defaults to id order and clamps the page size of keyset paginated lists
+func normalizePage(sort []pagination.SortField, limit int) ([]pagination.SortField, int) {
+	if len(sort) == 0 {
+		sort = []pagination.SortField{{Field: "id"}}
+	}
+
+	if limit < 1 {
+		limit = pagination.DefaultLimit
+	}
+	if limit > pagination.MaxLimit {
+		limit = pagination.MaxLimit
+	}
+
+	return sort, limit
+}
+
+// Page size of offset paginated lists such as the transaction history
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+func normalizeOffsetPage(page int, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	return page, limit
+}
@@ -1,34 +1,246 @@
 package services
 
 import (
+	"context"
+	"store-api-go/internal/apperrors"
 	"store-api-go/internal/models"
 	"store-api-go/internal/repositories"
+	"strings"
 )
 
 type ProductService struct {
-	repo *repositories.ProductRepo
+	repo              repositories.ProductRepository
+	categoryRepo      repositories.CategoryRepository
+	locationRepo      repositories.LocationRepository
+	taxClassRepo      repositories.TaxClassRepository
+	defaultLocationID int
 }
 
-func NewProductService(repo *repositories.ProductRepo) *ProductService {
-	return &ProductService{repo: repo}
+// NewProductService puts stock at defaultLocationID when a request doesn't name a location
+func NewProductService(repo repositories.ProductRepository, categoryRepo repositories.CategoryRepository, locationRepo repositories.LocationRepository, taxClassRepo repositories.TaxClassRepository, defaultLocationID int) *ProductService {
+	return &ProductService{
+		repo:              repo,
+		categoryRepo:      categoryRepo,
+		locationRepo:      locationRepo,
+		taxClassRepo:      taxClassRepo,
+		defaultLocationID: defaultLocationID,
+	}
 }
 
-func (s *ProductService) GetAll(name string) ([]models.Product, error) {
-	return s.repo.GetAll(name)
+func (s *ProductService) GetAll(ctx context.Context, filter models.ProductFilter) ([]models.Product, *models.CursorMeta, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetAll")
+	defer span.End()
+
+	filter.Sort, filter.Limit = normalizePage(filter.Sort, filter.Limit)
+
+	products, next, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return products, &models.CursorMeta{Limit: filter.Limit, NextCursor: next, HasMore: next != ""}, nil
 }
 
-func (s *ProductService) Create(data *models.Product) error {
-	return s.repo.Create(data)
+// Create puts the opening stock at the default location
+func (s *ProductService) Create(ctx context.Context, data *models.Product, actor string) error {
+	ctx, span := tracer.Start(ctx, "ProductService.Create")
+	defer span.End()
+
+	data.Name = strings.TrimSpace(data.Name)
+	err := validateUnique(ctx, data, data.Name, 0, s.repo.NameExists)
+	if err != nil {
+		return err
+	}
+
+	err = s.resolveCategory(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkTaxClass(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	locationID, err := resolveLocation(ctx, s.locationRepo, 0, s.defaultLocationID)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Create(ctx, data, locationID, actor)
 }
 
-func (s *ProductService) GetByID(id int) (*models.Product, error) {
-	return s.repo.GetByID(id)
+func (s *ProductService) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
 }
 
-func (s *ProductService) Update(Product *models.Product) error {
-	return s.repo.Update(Product)
+func (s *ProductService) Update(ctx context.Context, update *models.ProductUpdate) error {
+	ctx, span := tracer.Start(ctx, "ProductService.Update")
+	defer span.End()
+
+	product := &update.Product
+	product.Name = strings.TrimSpace(product.Name)
+	err := validateUnique(ctx, product, product.Name, product.ID, s.repo.NameExists)
+	if err != nil {
+		return err
+	}
+
+	if update.Stock != nil {
+		current, err := s.repo.GetByID(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		if *update.Stock != current.Stock {
+			var errs apperrors.FieldErrors
+			errs.Add("stock", "read_only", "stock can't be updated, use POST /api/products/%d/stock-adjustments", product.ID)
+			return errs.Err()
+		}
+	}
+
+	err = s.resolveCategory(ctx, product)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkTaxClass(ctx, product)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Update(ctx, product)
+	if err != nil {
+		return err
+	}
+
+	// stock isn't written by updates, report what is actually stored
+	updated, err := s.repo.GetByID(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+	*product = *updated
+
+	return nil
 }
 
-func (s *ProductService) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *ProductService) Delete(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "ProductService.Delete")
+	defer span.End()
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *ProductService) AdjustStock(ctx context.Context, id int, request models.StockAdjustmentRequest, actor string) (*models.StockMovement, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.AdjustStock")
+	defer span.End()
+
+	if request.Reason == "" {
+		request.Reason = models.StockReasonAdjustment
+	}
+	if request.Reason != models.StockReasonAdjustment && request.Reason != models.StockReasonReceipt {
+		return nil, apperrors.Validation("invalid_reason", "reason must be adjustment or receipt")
+	}
+	if request.Quantity == 0 {
+		return nil, apperrors.Validation("invalid_quantity", "quantity must not be zero")
+	}
+
+	locationID, err := resolveLocation(ctx, s.locationRepo, request.LocationID, s.defaultLocationID)
+	if err != nil {
+		return nil, err
+	}
+
+	movement := models.StockMovement{
+		ProductID:  id,
+		LocationID: locationID,
+		Reason:     request.Reason,
+		Actor:      actor,
+		Note:       request.Note,
+	}
+	err = s.repo.AdjustStock(ctx, &movement, func(current int) (int, error) {
+		if current+request.Quantity < 0 {
+			return 0, apperrors.InsufficientStock("out_of_stock", "cannot remove %d, only %d in stock", -request.Quantity, current)
+		}
+		return request.Quantity, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &movement, nil
+}
+
+func (s *ProductService) Stocktake(ctx context.Context, id int, request models.StocktakeRequest, actor string) (*models.StockMovement, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.Stocktake")
+	defer span.End()
+
+	if request.CountedQuantity < 0 {
+		return nil, apperrors.Validation("invalid_quantity", "counted_quantity must not be negative")
+	}
+
+	locationID, err := resolveLocation(ctx, s.locationRepo, request.LocationID, s.defaultLocationID)
+	if err != nil {
+		return nil, err
+	}
+
+	movement := models.StockMovement{
+		ProductID:  id,
+		LocationID: locationID,
+		Reason:     models.StockReasonStocktake,
+		Actor:      actor,
+		Note:       request.Note,
+	}
+	err = s.repo.AdjustStock(ctx, &movement, func(current int) (int, error) {
+		return request.CountedQuantity - current, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &movement, nil
+}
+
+func (s *ProductService) GetStockHistory(ctx context.Context, id int, page int, limit int) ([]models.StockMovement, *models.PageMeta, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetStockHistory")
+	defer span.End()
+
+	_, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page, limit = normalizeOffsetPage(page, limit)
+	movements, total, err := s.repo.GetStockHistory(ctx, id, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return movements, &models.PageMeta{Page: page, Limit: limit, Total: total}, nil
+}
+
+// resolveCategory checks the product's category exists and fills in its name
+func (s *ProductService) resolveCategory(ctx context.Context, product *models.Product) error {
+	product.CategoryName = ""
+	if product.CategoryID == nil {
+		return nil
+	}
+
+	category, err := s.categoryRepo.GetByID(ctx, *product.CategoryID)
+	if err != nil {
+		return err
+	}
+	product.CategoryName = category.Name
+
+	return nil
+}
+
+// checkTaxClass checks the product's tax class exists, products without one use the default rate
+func (s *ProductService) checkTaxClass(ctx context.Context, product *models.Product) error {
+	if product.TaxClassID == nil {
+		return nil
+	}
+
+	_, err := s.taxClassRepo.GetByID(ctx, *product.TaxClassID)
+	return err
 }
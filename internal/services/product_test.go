@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories/memory"
+	"testing"
+)
+
+func TestCreateProductLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		price int
+		stock int
+		field string
+	}{
+		{"price", maxAmount + 1, 0, "price"},
+		{"stock", 1000, maxAmount + 1, "stock"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			products, _ := newTestServices(memory.NewStore())
+
+			product := models.Product{Name: "Coffee", Price: test.price, Stock: test.stock}
+			err := products.Create(context.Background(), &product, "test")
+			appErr := apperrors.As(err)
+			if appErr == nil || appErr.Kind != apperrors.KindValidation || len(appErr.Fields) != 1 ||
+				appErr.Fields[0].Field != test.field || appErr.Fields[0].Code != "max" {
+				t.Errorf("got %v, want a max error on %s", err, test.field)
+			}
+		})
+	}
+}
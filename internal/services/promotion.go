@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+	"strings"
+)
+
+type PromotionService struct {
+	repo         repositories.PromotionRepository
+	productRepo  repositories.ProductRepository
+	categoryRepo repositories.CategoryRepository
+}
+
+func NewPromotionService(repo repositories.PromotionRepository, productRepo repositories.ProductRepository, categoryRepo repositories.CategoryRepository) *PromotionService {
+	return &PromotionService{repo: repo, productRepo: productRepo, categoryRepo: categoryRepo}
+}
+
+func (s *PromotionService) GetAll(ctx context.Context) ([]models.Promotion, error) {
+	ctx, span := tracer.Start(ctx, "PromotionService.GetAll")
+	defer span.End()
+
+	return s.repo.GetAll(ctx)
+}
+
+func (s *PromotionService) GetByID(ctx context.Context, id int) (*models.Promotion, error) {
+	ctx, span := tracer.Start(ctx, "PromotionService.GetByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *PromotionService) Create(ctx context.Context, promotion *models.Promotion) error {
+	ctx, span := tracer.Start(ctx, "PromotionService.Create")
+	defer span.End()
+
+	if err := s.validate(ctx, promotion); err != nil {
+		return err
+	}
+	// new promotions run unless created switched off
+	if promotion.Active == nil {
+		active := true
+		promotion.Active = &active
+	}
+
+	return s.repo.Create(ctx, promotion)
+}
+
+func (s *PromotionService) Update(ctx context.Context, promotion *models.Promotion) error {
+	ctx, span := tracer.Start(ctx, "PromotionService.Update")
+	defer span.End()
+
+	if err := s.validate(ctx, promotion); err != nil {
+		return err
+	}
+	// leaving active out keeps the promotion switched on or off as it is
+	if promotion.Active == nil {
+		current, err := s.repo.GetByID(ctx, promotion.ID)
+		if err != nil {
+			return err
+		}
+		promotion.Active = current.Active
+	}
+
+	return s.repo.Update(ctx, promotion)
+}
+
+func (s *PromotionService) Delete(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "PromotionService.Delete")
+	defer span.End()
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *PromotionService) validate(ctx context.Context, promotion *models.Promotion) error {
+	promotion.Name = strings.TrimSpace(promotion.Name)
+	if promotion.Name == "" {
+		return apperrors.Validation("name_required", "name is required")
+	}
+	promotion.CouponCode = normalizeCoupon(promotion.CouponCode)
+
+	switch promotion.Type {
+	case models.PromotionPercentage:
+		if promotion.Value < 1 || promotion.Value > 100 {
+			return apperrors.Validation("invalid_value", "percentage value must be between 1 and 100")
+		}
+		promotion.BuyQuantity, promotion.GetQuantity = 0, 0
+	case models.PromotionFixed:
+		if promotion.Value <= 0 {
+			return apperrors.Validation("invalid_value", "fixed value must be greater than zero")
+		}
+		promotion.BuyQuantity, promotion.GetQuantity = 0, 0
+	case models.PromotionBuyXGetY:
+		if promotion.BuyQuantity < 1 || promotion.GetQuantity < 1 {
+			return apperrors.Validation("invalid_quantity", "buy_quantity and get_quantity must be at least 1")
+		}
+		promotion.Value = 0
+	default:
+		return apperrors.Validation("invalid_promotion_type", "type must be %s, %s or %s", models.PromotionPercentage, models.PromotionFixed, models.PromotionBuyXGetY)
+	}
+
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return apperrors.Validation("invalid_period", "ends_at must be after starts_at")
+	}
+
+	if promotion.ProductID != nil && promotion.CategoryID != nil {
+		return apperrors.Validation("invalid_scope", "a promotion applies to a product or a category, not both")
+	}
+	if promotion.ProductID != nil {
+		if _, err := s.productRepo.GetByID(ctx, *promotion.ProductID); err != nil {
+			return err
+		}
+	}
+	if promotion.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(ctx, *promotion.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func normalizeCoupon(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// eligiblePromotions keeps the running promotions that apply without a coupon plus the
+// ones whose coupon was given. Every coupon given must belong to a running promotion.
+func eligiblePromotions(running []models.Promotion, coupons []string) ([]models.Promotion, error) {
+	given := make(map[string]bool, len(coupons))
+	for _, code := range coupons {
+		if code = normalizeCoupon(code); code != "" {
+			given[code] = true
+		}
+	}
+
+	used := make(map[string]bool, len(given))
+	eligible := make([]models.Promotion, 0, len(running))
+	for _, promotion := range running {
+		if promotion.CouponCode != "" {
+			if !given[promotion.CouponCode] {
+				continue
+			}
+			used[promotion.CouponCode] = true
+		}
+		eligible = append(eligible, promotion)
+	}
+
+	for code := range given {
+		if !used[code] {
+			return nil, apperrors.Validation("invalid_coupon", "coupon code %q is not valid", code)
+		}
+	}
+
+	return eligible, nil
+}
+
+// applyPromotions gives every line the single promotion with the biggest discount,
+// promotions don't stack. Promotions come ordered by id so ties go to the oldest.
+// The transaction total becomes the sum of the discounted lines.
+func applyPromotions(transaction *models.Transaction, promotions []models.Promotion) {
+	transaction.GrossAmount = 0
+	transaction.DiscountAmount = 0
+	transaction.TotalAmount = 0
+
+	for i := range transaction.Details {
+		detail := &transaction.Details[i]
+		detail.DiscountAmount = 0
+		detail.PromotionID = nil
+		detail.PromotionName = ""
+
+		for _, promotion := range promotions {
+			if !promotionCovers(promotion, detail) {
+				continue
+			}
+			discount := promotionDiscount(promotion, detail)
+			if discount > detail.DiscountAmount {
+				id := promotion.ID
+				detail.DiscountAmount = discount
+				detail.PromotionID = &id
+				detail.PromotionName = promotion.Name
+			}
+		}
+
+		detail.Subtotal = detail.GrossAmount - detail.DiscountAmount
+		transaction.GrossAmount += detail.GrossAmount
+		transaction.DiscountAmount += detail.DiscountAmount
+		transaction.TotalAmount += detail.Subtotal
+	}
+}
+
+func promotionCovers(promotion models.Promotion, detail *models.TransactionDetail) bool {
+	switch {
+	case promotion.ProductID != nil:
+		return *promotion.ProductID == detail.ProductID
+	case promotion.CategoryID != nil:
+		return detail.CategoryID != nil && *promotion.CategoryID == *detail.CategoryID
+	default:
+		return true
+	}
+}
+
+// promotionDiscount never takes more than the line is worth
+func promotionDiscount(promotion models.Promotion, detail *models.TransactionDetail) int {
+	discount := 0
+	switch promotion.Type {
+	case models.PromotionPercentage:
+		discount = detail.GrossAmount * promotion.Value / 100
+	case models.PromotionFixed:
+		discount = promotion.Value * detail.Quantity
+	case models.PromotionBuyXGetY:
+		free := detail.Quantity / (promotion.BuyQuantity + promotion.GetQuantity) * promotion.GetQuantity
+		discount = free * detail.UnitPrice
+	}
+
+	return min(discount, detail.GrossAmount)
+}
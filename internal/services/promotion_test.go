@@ -0,0 +1,87 @@
+package services
+
+import (
+	"store-api-go/internal/models"
+	"testing"
+)
+
+func TestApplyPromotions(t *testing.T) {
+	drinks := 7
+	coffee := 1
+	tests := []struct {
+		name       string
+		promotions []models.Promotion
+		quantity   int
+		unitPrice  int
+		discount   int
+		promotion  int
+	}{
+		{"no promotion", nil, 2, 1000, 0, 0},
+		{"biggest discount wins", []models.Promotion{
+			{ID: 1, Type: models.PromotionPercentage, Value: 10},
+			{ID: 2, Type: models.PromotionFixed, Value: 250, ProductID: &coffee},
+			{ID: 3, Type: models.PromotionPercentage, Value: 20, CategoryID: &drinks},
+		}, 2, 1000, 500, 2},
+		{"ties go to the oldest", []models.Promotion{
+			{ID: 4, Type: models.PromotionPercentage, Value: 10},
+			{ID: 5, Type: models.PromotionFixed, Value: 100},
+		}, 2, 1000, 200, 4},
+		{"other product", []models.Promotion{
+			{ID: 6, Type: models.PromotionPercentage, Value: 50, ProductID: &drinks},
+		}, 2, 1000, 0, 0},
+		{"fixed is capped at the line", []models.Promotion{
+			{ID: 7, Type: models.PromotionFixed, Value: 5000},
+		}, 2, 1000, 2000, 7},
+		{"buy 2 get 1", []models.Promotion{
+			{ID: 8, Type: models.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1},
+		}, 7, 1000, 2000, 8},
+		{"buy 2 get 1 short of a set", []models.Promotion{
+			{ID: 9, Type: models.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1},
+		}, 2, 1000, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transaction := models.Transaction{Details: []models.TransactionDetail{{
+				ProductID:   coffee,
+				CategoryID:  &drinks,
+				Quantity:    test.quantity,
+				UnitPrice:   test.unitPrice,
+				GrossAmount: test.quantity * test.unitPrice,
+			}}}
+			applyPromotions(&transaction, test.promotions)
+
+			detail := transaction.Details[0]
+			if detail.DiscountAmount != test.discount {
+				t.Errorf("discount %d, want %d", detail.DiscountAmount, test.discount)
+			}
+			if got := detail.PromotionID; (got == nil) != (test.promotion == 0) || (got != nil && *got != test.promotion) {
+				t.Errorf("promotion %v, want %d", got, test.promotion)
+			}
+			if transaction.TotalAmount != detail.GrossAmount-test.discount {
+				t.Errorf("total %d, want %d", transaction.TotalAmount, detail.GrossAmount-test.discount)
+			}
+		})
+	}
+}
+
+// TestApplyPromotionsBuyXGetYPerLine checks units of different lines don't add up to a free one
+func TestApplyPromotionsBuyXGetYPerLine(t *testing.T) {
+	promotions := []models.Promotion{{ID: 1, Type: models.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1}}
+	transaction := models.Transaction{Details: []models.TransactionDetail{
+		{ProductID: 1, Quantity: 4, UnitPrice: 1000, GrossAmount: 4000},
+		{ProductID: 2, Quantity: 2, UnitPrice: 500, GrossAmount: 1000},
+	}}
+	applyPromotions(&transaction, promotions)
+
+	if got := transaction.Details[0].DiscountAmount; got != 1000 {
+		t.Errorf("first line discount %d, want 1000", got)
+	}
+	if got := transaction.Details[1].DiscountAmount; got != 0 {
+		t.Errorf("second line discount %d, want 0", got)
+	}
+	if transaction.GrossAmount != 5000 || transaction.DiscountAmount != 1000 || transaction.TotalAmount != 4000 {
+		t.Errorf("gross %d discount %d total %d, want 5000 1000 4000",
+			transaction.GrossAmount, transaction.DiscountAmount, transaction.TotalAmount)
+	}
+}
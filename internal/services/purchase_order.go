@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+)
+
+type PurchaseOrderService struct {
+	repo              repositories.PurchaseOrderRepository
+	supplierRepo      repositories.SupplierRepository
+	locationRepo      repositories.LocationRepository
+	defaultLocationID int
+}
+
+// NewPurchaseOrderService delivers to defaultLocationID when an order doesn't name a location
+func NewPurchaseOrderService(repo repositories.PurchaseOrderRepository, supplierRepo repositories.SupplierRepository, locationRepo repositories.LocationRepository, defaultLocationID int) *PurchaseOrderService {
+	return &PurchaseOrderService{repo: repo, supplierRepo: supplierRepo, locationRepo: locationRepo, defaultLocationID: defaultLocationID}
+}
+
+func (s *PurchaseOrderService) GetAll(ctx context.Context, filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, *models.PageMeta, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.GetAll")
+	defer span.End()
+
+	switch filter.Status {
+	case "", models.PurchaseOrderDraft, models.PurchaseOrderSent, models.PurchaseOrderPartiallyReceived, models.PurchaseOrderClosed:
+	default:
+		return nil, nil, apperrors.Validation("invalid_status", "unknown purchase order status %q", filter.Status)
+	}
+
+	filter.Page, filter.Limit = normalizeOffsetPage(filter.Page, filter.Limit)
+
+	orders, total, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return orders, &models.PageMeta{Page: filter.Page, Limit: filter.Limit, Total: total}, nil
+}
+
+func (s *PurchaseOrderService) GetByID(ctx context.Context, id int) (*models.PurchaseOrder, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.GetByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// OnOrder lists what has been sent to suppliers and not received yet, per product
+func (s *PurchaseOrderService) OnOrder(ctx context.Context) ([]models.OnOrderProduct, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.OnOrder")
+	defer span.End()
+
+	return s.repo.OnOrder(ctx)
+}
+
+// Create stores the order as a draft, it can be received once it is sent
+func (s *PurchaseOrderService) Create(ctx context.Context, order *models.PurchaseOrder, actor string) (*models.PurchaseOrder, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.Create")
+	defer span.End()
+
+	if _, err := s.supplierRepo.GetByID(ctx, order.SupplierID); err != nil {
+		return nil, err
+	}
+
+	locationID, err := resolveLocation(ctx, s.locationRepo, order.LocationID, s.defaultLocationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(order.Lines) == 0 {
+		return nil, apperrors.Validation("empty_purchase_order", "purchase order has no lines")
+	}
+	seen := make(map[int]bool)
+	for _, line := range order.Lines {
+		if seen[line.ProductID] {
+			return nil, apperrors.Validation("duplicate_product", "product id %d is listed more than once", line.ProductID)
+		}
+		seen[line.ProductID] = true
+
+		if line.QuantityOrdered <= 0 {
+			return nil, apperrors.Validation("invalid_quantity", "quantity_ordered must be greater than zero")
+		}
+		if line.UnitCost < 0 {
+			return nil, apperrors.Validation("invalid_unit_cost", "unit_cost must not be negative")
+		}
+	}
+
+	order.LocationID = locationID
+	order.CreatedBy = actor
+	if err := s.repo.Create(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, order.ID)
+}
+
+func (s *PurchaseOrderService) Send(ctx context.Context, id int) (*models.PurchaseOrder, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.Send")
+	defer span.End()
+
+	return s.repo.UpdateStatus(ctx, id, func(order *models.PurchaseOrder) error {
+		if order.Status != models.PurchaseOrderDraft {
+			return apperrors.Conflict("purchase_order_not_draft", "Purchase order is %s, only drafts can be sent", order.Status)
+		}
+		order.Status = models.PurchaseOrderSent
+		return nil
+	})
+}
+
+// Close stops an order from being received any further, whatever is still outstanding
+// is no longer on order
+func (s *PurchaseOrderService) Close(ctx context.Context, id int) (*models.PurchaseOrder, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.Close")
+	defer span.End()
+
+	return s.repo.UpdateStatus(ctx, id, func(order *models.PurchaseOrder) error {
+		if order.Status == models.PurchaseOrderClosed {
+			return apperrors.Conflict("purchase_order_closed", "Purchase order is already closed")
+		}
+		order.Status = models.PurchaseOrderClosed
+		return nil
+	})
+}
+
+func (s *PurchaseOrderService) Receive(ctx context.Context, id int, request models.ReceiveRequest, actor string) (*models.PurchaseOrder, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.Receive")
+	defer span.End()
+
+	for _, item := range request.Items {
+		if item.Quantity <= 0 {
+			return nil, apperrors.Validation("invalid_quantity", "received quantity must be greater than zero")
+		}
+	}
+
+	receipt := models.GoodsReceipt{Note: request.Note, ReceivedBy: actor}
+	return s.repo.Receive(ctx, id, &receipt, func(order *models.PurchaseOrder, receipt *models.GoodsReceipt) error {
+		return planReceipt(order, request.Items, receipt)
+	})
+}
+
+// planReceipt matches the delivered items to the order's lines, or takes everything still
+// outstanding when no items are given, and works out the order's new status
+func planReceipt(order *models.PurchaseOrder, items []models.GoodsReceiptItem, receipt *models.GoodsReceipt) error {
+	if order.Status != models.PurchaseOrderSent && order.Status != models.PurchaseOrderPartiallyReceived {
+		return apperrors.Conflict("purchase_order_not_sent", "Purchase order is %s, only sent orders can be received", order.Status)
+	}
+
+	received := make(map[int]int)
+	if len(items) == 0 {
+		for _, line := range order.Lines {
+			received[line.ProductID] = line.QuantityOrdered - line.QuantityReceived
+		}
+	}
+	for _, item := range items {
+		received[item.ProductID] += item.Quantity
+	}
+
+	receipt.Items = make([]models.GoodsReceiptItem, 0, len(order.Lines))
+	complete := true
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		quantity, exists := received[line.ProductID]
+		delete(received, line.ProductID)
+
+		if quantity > line.QuantityOrdered-line.QuantityReceived {
+			return apperrors.Validation("receipt_exceeds_remaining", "product id %d: only %d left to receive", line.ProductID, line.QuantityOrdered-line.QuantityReceived)
+		}
+		if exists && quantity > 0 {
+			line.QuantityReceived += quantity
+			receipt.Items = append(receipt.Items, models.GoodsReceiptItem{
+				LineID:    line.ID,
+				ProductID: line.ProductID,
+				Quantity:  quantity,
+			})
+		}
+		if line.QuantityReceived < line.QuantityOrdered {
+			complete = false
+		}
+	}
+
+	for productID := range received {
+		return apperrors.Validation("product_not_on_order", "product id %d is not on this purchase order", productID)
+	}
+	if len(receipt.Items) == 0 {
+		return apperrors.Conflict("nothing_to_receive", "nothing left to receive")
+	}
+
+	order.Status = models.PurchaseOrderPartiallyReceived
+	if complete {
+		order.Status = models.PurchaseOrderClosed
+	}
+
+	return nil
+}
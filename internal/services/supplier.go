@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+	"strings"
+)
+
+type SupplierService struct {
+	repo repositories.SupplierRepository
+}
+
+func NewSupplierService(repo repositories.SupplierRepository) *SupplierService {
+	return &SupplierService{repo: repo}
+}
+
+func (s *SupplierService) GetAll(ctx context.Context) ([]models.Supplier, error) {
+	ctx, span := tracer.Start(ctx, "SupplierService.GetAll")
+	defer span.End()
+
+	return s.repo.GetAll(ctx)
+}
+
+func (s *SupplierService) GetByID(ctx context.Context, id int) (*models.Supplier, error) {
+	ctx, span := tracer.Start(ctx, "SupplierService.GetByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *SupplierService) Create(ctx context.Context, supplier *models.Supplier) error {
+	ctx, span := tracer.Start(ctx, "SupplierService.Create")
+	defer span.End()
+
+	supplier.Name = strings.TrimSpace(supplier.Name)
+	if supplier.Name == "" {
+		return apperrors.Validation("name_required", "name is required")
+	}
+
+	return s.repo.Create(ctx, supplier)
+}
+
+func (s *SupplierService) Update(ctx context.Context, supplier *models.Supplier) error {
+	ctx, span := tracer.Start(ctx, "SupplierService.Update")
+	defer span.End()
+
+	supplier.Name = strings.TrimSpace(supplier.Name)
+	if supplier.Name == "" {
+		return apperrors.Validation("name_required", "name is required")
+	}
+
+	return s.repo.Update(ctx, supplier)
+}
+
+func (s *SupplierService) Delete(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "SupplierService.Delete")
+	defer span.End()
+
+	return s.repo.Delete(ctx, id)
+}
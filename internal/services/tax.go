@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+	"strings"
+)
+
+// MaxTaxRate is 100% in basis points
+const MaxTaxRate = 10000
+
+func IsValidTaxMode(mode string) bool {
+	return mode == models.TaxInclusive || mode == models.TaxExclusive
+}
+
+type TaxClassService struct {
+	repo repositories.TaxClassRepository
+}
+
+func NewTaxClassService(repo repositories.TaxClassRepository) *TaxClassService {
+	return &TaxClassService{repo: repo}
+}
+
+func (s *TaxClassService) GetAll(ctx context.Context) ([]models.TaxClass, error) {
+	ctx, span := tracer.Start(ctx, "TaxClassService.GetAll")
+	defer span.End()
+
+	return s.repo.GetAll(ctx)
+}
+
+func (s *TaxClassService) GetByID(ctx context.Context, id int) (*models.TaxClass, error) {
+	ctx, span := tracer.Start(ctx, "TaxClassService.GetByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *TaxClassService) Create(ctx context.Context, taxClass *models.TaxClass) error {
+	ctx, span := tracer.Start(ctx, "TaxClassService.Create")
+	defer span.End()
+
+	if err := validateTaxClass(taxClass); err != nil {
+		return err
+	}
+
+	return s.repo.Create(ctx, taxClass)
+}
+
+func (s *TaxClassService) Update(ctx context.Context, taxClass *models.TaxClass) error {
+	ctx, span := tracer.Start(ctx, "TaxClassService.Update")
+	defer span.End()
+
+	if err := validateTaxClass(taxClass); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, taxClass)
+}
+
+func (s *TaxClassService) Delete(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "TaxClassService.Delete")
+	defer span.End()
+
+	return s.repo.Delete(ctx, id)
+}
+
+func validateTaxClass(taxClass *models.TaxClass) error {
+	taxClass.Name = strings.TrimSpace(taxClass.Name)
+	if taxClass.Name == "" {
+		return apperrors.Validation("name_required", "name is required")
+	}
+	if taxClass.Rate < 0 || taxClass.Rate > MaxTaxRate {
+		return apperrors.Validation("invalid_rate", "rate must be between 0 and 10000 basis points")
+	}
+
+	return nil
+}
+
+// applyTax works out each line's tax on its discounted amount. Lines without a tax class
+// are taxed at defaultRate. Inclusive prices already contain the tax, exclusive prices
+// have it added to the line. Tax is rounded half up per line.
+func applyTax(transaction *models.Transaction, mode string, defaultRate int) {
+	transaction.TaxMode = mode
+	transaction.TaxAmount = 0
+	transaction.TotalAmount = 0
+
+	for i := range transaction.Details {
+		detail := &transaction.Details[i]
+		if detail.TaxClassID == nil {
+			detail.TaxRate = defaultRate
+		}
+
+		amount := detail.GrossAmount - detail.DiscountAmount
+		if mode == models.TaxInclusive {
+			detail.TaxAmount = divideRounded(amount*detail.TaxRate, MaxTaxRate+detail.TaxRate)
+			detail.Subtotal = amount
+		} else {
+			detail.TaxAmount = divideRounded(amount*detail.TaxRate, MaxTaxRate)
+			detail.Subtotal = amount + detail.TaxAmount
+		}
+
+		transaction.TaxAmount += detail.TaxAmount
+		transaction.TotalAmount += detail.Subtotal
+	}
+}
+
+// divideRounded divides non negative integers rounding half up
+func divideRounded(numerator int, denominator int) int {
+	return (numerator + denominator/2) / denominator
+}
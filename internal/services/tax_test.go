@@ -0,0 +1,84 @@
+package services
+
+import (
+	"store-api-go/internal/models"
+	"testing"
+)
+
+func TestApplyTax(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		rate     int
+		amount   int
+		tax      int
+		subtotal int
+	}{
+		{"inclusive", models.TaxInclusive, 1000, 1100, 100, 1100},
+		{"inclusive rounds half up", models.TaxInclusive, 1000, 1000, 91, 1000},
+		{"exclusive", models.TaxExclusive, 1000, 1000, 100, 1100},
+		{"exclusive rounds half up", models.TaxExclusive, 1000, 1005, 101, 1106},
+		{"inclusive rate 0", models.TaxInclusive, 0, 1000, 0, 1000},
+		{"exclusive rate 0", models.TaxExclusive, 0, 1000, 0, 1000},
+		{"inclusive max rate", models.TaxInclusive, MaxTaxRate, 1001, 501, 1001},
+		{"exclusive max rate", models.TaxExclusive, MaxTaxRate, 1000, 1000, 2000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the discount comes off before the tax
+			transaction := models.Transaction{Details: []models.TransactionDetail{
+				{GrossAmount: test.amount + 100, DiscountAmount: 100},
+			}}
+			applyTax(&transaction, test.mode, test.rate)
+
+			detail := transaction.Details[0]
+			if detail.TaxRate != test.rate || detail.TaxAmount != test.tax || detail.Subtotal != test.subtotal {
+				t.Errorf("rate %d tax %d subtotal %d, want %d %d %d",
+					detail.TaxRate, detail.TaxAmount, detail.Subtotal, test.rate, test.tax, test.subtotal)
+			}
+			if transaction.TaxAmount != test.tax || transaction.TotalAmount != test.subtotal {
+				t.Errorf("transaction tax %d total %d, want %d %d",
+					transaction.TaxAmount, transaction.TotalAmount, test.tax, test.subtotal)
+			}
+		})
+	}
+}
+
+func TestApplyTaxKeepsClassRate(t *testing.T) {
+	taxClassID := 1
+	transaction := models.Transaction{Details: []models.TransactionDetail{
+		{TaxClassID: &taxClassID, TaxRate: 500, GrossAmount: 1000},
+		{GrossAmount: 1000},
+	}}
+	applyTax(&transaction, models.TaxExclusive, 1000)
+
+	if transaction.Details[0].TaxAmount != 50 || transaction.Details[1].TaxAmount != 100 {
+		t.Errorf("taxes %d and %d, want 50 from the class and 100 from the default",
+			transaction.Details[0].TaxAmount, transaction.Details[1].TaxAmount)
+	}
+	if transaction.TaxAmount != 150 || transaction.TotalAmount != 2150 {
+		t.Errorf("tax %d total %d, want 150 and 2150", transaction.TaxAmount, transaction.TotalAmount)
+	}
+}
+
+func TestDivideRounded(t *testing.T) {
+	tests := []struct {
+		numerator, denominator, want int
+	}{
+		{0, 7, 0},
+		{4, 10, 0},
+		{5, 10, 1},
+		{14, 10, 1},
+		{15, 10, 2},
+		{20, 10, 2},
+		{1, 3, 0},
+		{2, 3, 1},
+	}
+
+	for _, test := range tests {
+		if got := divideRounded(test.numerator, test.denominator); got != test.want {
+			t.Errorf("divideRounded(%d, %d) = %d, want %d", test.numerator, test.denominator, got, test.want)
+		}
+	}
+}
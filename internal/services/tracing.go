@@ -0,0 +1,7 @@
+package services
+
+import "go.opentelemetry.io/otel"
+
+// tracer gives every service call a span, nested in the request's span and parent to
+// the call's SQL statements
+var tracer = otel.Tracer("store-api-go/internal/services")
@@ -1,22 +1,267 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"math"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/metrics"
 	"store-api-go/internal/models"
 	"store-api-go/internal/repositories"
+	"store-api-go/internal/validate"
+	"time"
 )
 
+// maxAmount is the largest amount the INTEGER columns hold
+const maxAmount = math.MaxInt32
+
+var errAmountTooLarge = apperrors.Validation("amount_too_large", "transaction amounts must be at most %d", maxAmount)
+
 type TransactionService struct {
-	repo *repositories.TransactionRepo
+	repo              repositories.TransactionRepository
+	locationRepo      repositories.LocationRepository
+	promotionRepo     repositories.PromotionRepository
+	defaultLocationID int
+	taxMode           string
+	defaultTaxRate    int
+	location          *time.Location
+}
+
+// NewTransactionService sells from defaultLocationID when a checkout doesn't name a location.
+// Prices are taxed according to taxMode, at defaultTaxRate for products without a tax class.
+// Report days are the days of location.
+func NewTransactionService(repo repositories.TransactionRepository, locationRepo repositories.LocationRepository, promotionRepo repositories.PromotionRepository, defaultLocationID int, taxMode string, defaultTaxRate int, location *time.Location) *TransactionService {
+	return &TransactionService{
+		repo:              repo,
+		locationRepo:      locationRepo,
+		promotionRepo:     promotionRepo,
+		defaultLocationID: defaultLocationID,
+		taxMode:           taxMode,
+		defaultTaxRate:    defaultTaxRate,
+		location:          location,
+	}
+}
+
+// Today is the current date in the store's time zone, as reports take it
+func (s *TransactionService) Today() string {
+	return time.Now().In(s.location).Format("2006-01-02")
 }
 
-func NewTransactionService(repo *repositories.TransactionRepo) *TransactionService {
-	return &TransactionService{repo: repo}
+func (s *TransactionService) GetAll(ctx context.Context, filter models.TransactionFilter) ([]models.Transaction, *models.PageMeta, error) {
+	ctx, span := tracer.Start(ctx, "TransactionService.GetAll")
+	defer span.End()
+
+	filter.Page, filter.Limit = normalizeOffsetPage(filter.Page, filter.Limit)
+
+	transactions, total, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return transactions, &models.PageMeta{Page: filter.Page, Limit: filter.Limit, Total: total}, nil
 }
 
-func (s *TransactionService) Checkout(items []models.CheckoutItem) (*models.Transaction, error) {
-	return s.repo.CreateTransaction(items)
+func (s *TransactionService) GetByID(ctx context.Context, id int) (*models.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "TransactionService.GetByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
 }
 
-func (s *TransactionService) Report(startDate string, endDate string) (*models.ReportResponse, error) {
-	return s.repo.Report(startDate, endDate)
+func (s *TransactionService) Checkout(ctx context.Context, request models.CheckoutRequest, actor string) (*models.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "TransactionService.Checkout")
+	defer span.End()
+
+	errs, err := validate.Struct(request)
+	if err != nil {
+		return nil, err
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	if err := validatePayments(request.Payments); err != nil {
+		return nil, err
+	}
+
+	locationID, err := resolveLocation(ctx, s.locationRepo, request.LocationID, s.defaultLocationID)
+	if err != nil {
+		return nil, err
+	}
+	request.LocationID = locationID
+
+	running, err := s.promotionRepo.GetRunning(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	promotions, err := eligiblePromotions(running, request.CouponCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.repo.CreateTransaction(ctx, request, actor, func(transaction *models.Transaction) error {
+		// price times quantity fits an int but the sum of several such lines may not
+		for _, detail := range transaction.Details {
+			if detail.GrossAmount > maxAmount {
+				return errAmountTooLarge
+			}
+		}
+		applyPromotions(transaction, promotions)
+		applyTax(transaction, s.taxMode, s.defaultTaxRate)
+		if transaction.GrossAmount > maxAmount || transaction.TotalAmount > maxAmount {
+			return errAmountTooLarge
+		}
+		return settlePayments(transaction)
+	})
+	if errors.Is(err, apperrors.ErrInsufficientStock) {
+		metrics.CheckoutOutOfStock()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.CheckoutCompleted(transaction.TotalAmount)
+	return transaction, nil
+}
+
+func (s *TransactionService) Refund(ctx context.Context, transactionID int, request models.RefundRequest, actor string) (*models.Refund, error) {
+	ctx, span := tracer.Start(ctx, "TransactionService.Refund")
+	defer span.End()
+
+	for _, item := range request.Items {
+		if item.Quantity <= 0 {
+			return nil, apperrors.Validation("invalid_quantity", "refund quantity must be greater than zero")
+		}
+	}
+
+	return s.repo.CreateRefund(ctx, transactionID, request, actor, func(transaction *models.Transaction, refund *models.Refund) error {
+		return planRefund(transaction, request.Items, refund)
+	})
+}
+
+func (s *TransactionService) Report(ctx context.Context, startDate string, endDate string) (*models.ReportResponse, error) {
+	ctx, span := tracer.Start(ctx, "TransactionService.Report")
+	defer span.End()
+
+	return s.repo.Report(ctx, startDate, endDate)
+}
+
+func (s *TransactionService) TaxReport(ctx context.Context, startDate string, endDate string) (*models.TaxReport, error) {
+	ctx, span := tracer.Start(ctx, "TransactionService.TaxReport")
+	defer span.End()
+
+	return s.repo.TaxReport(ctx, startDate, endDate)
+}
+
+func validatePayments(payments []models.Payment) error {
+	if len(payments) == 0 {
+		return apperrors.Validation("payment_required", "at least one payment is required")
+	}
+
+	for _, payment := range payments {
+		switch payment.Method {
+		case models.PaymentMethodCash, models.PaymentMethodCard, models.PaymentMethodQRIS:
+		default:
+			return apperrors.Validation("invalid_payment_method", "unknown payment method %q", payment.Method)
+		}
+
+		if payment.Amount <= 0 || payment.Amount > maxAmount {
+			return apperrors.Validation("invalid_payment_amount", "payment amount must be between 1 and %d", maxAmount)
+		}
+	}
+
+	return nil
+}
+
+// settlePayments checks the payments cover the total and works out the change.
+// Change can only be handed back from cash, card and QR payments must not overpay.
+func settlePayments(transaction *models.Transaction) error {
+	paid := 0
+	cash := 0
+	for _, payment := range transaction.Payments {
+		paid += payment.Amount
+		if payment.Method == models.PaymentMethodCash {
+			cash += payment.Amount
+		}
+	}
+
+	if paid > maxAmount {
+		return errAmountTooLarge
+	}
+	if paid < transaction.TotalAmount {
+		return apperrors.Validation("insufficient_payment", "payment of %d is less than the total of %d", paid, transaction.TotalAmount)
+	}
+
+	change := paid - transaction.TotalAmount
+	if change > cash {
+		return apperrors.Validation("overpayment", "non-cash payments exceed the total, change can only be given for cash")
+	}
+
+	transaction.PaidAmount = paid
+	transaction.ChangeAmount = change
+
+	return nil
+}
+
+// planRefund validates the requested lines against what is left to refund on each
+// transaction detail. With no lines requested the whole remainder is refunded.
+func planRefund(transaction *models.Transaction, items []models.RefundItem, refund *models.Refund) error {
+	details := make(map[int]models.TransactionDetail, len(transaction.Details))
+	for _, detail := range transaction.Details {
+		details[detail.ID] = detail
+	}
+
+	requested := make(map[int]int)
+	order := make([]int, 0)
+	if len(items) == 0 {
+		for _, detail := range transaction.Details {
+			if remaining := detail.Quantity - detail.RefundedQuantity; remaining > 0 {
+				requested[detail.ID] = remaining
+				order = append(order, detail.ID)
+			}
+		}
+	}
+	for _, item := range items {
+		if _, exists := details[item.TransactionDetailID]; !exists {
+			return apperrors.Validation("invalid_transaction_detail", "transaction detail id %d does not belong to transaction %d", item.TransactionDetailID, transaction.ID)
+		}
+		if _, exists := requested[item.TransactionDetailID]; !exists {
+			order = append(order, item.TransactionDetailID)
+		}
+		requested[item.TransactionDetailID] += item.Quantity
+	}
+
+	if len(order) == 0 {
+		return apperrors.Conflict("transaction_fully_refunded", "transaction has already been fully refunded")
+	}
+
+	refund.Amount = 0
+	refund.Items = make([]models.RefundItem, 0, len(order))
+	for _, detailID := range order {
+		detail := details[detailID]
+		quantity := requested[detailID]
+
+		remaining := detail.Quantity - detail.RefundedQuantity
+		if quantity > remaining {
+			return apperrors.Validation("refund_exceeds_remaining", "transaction detail id %d has only %d left to refund", detailID, remaining)
+		}
+
+		// Refund the line and its tax pro rata, the last unit takes whatever is left so rounding never drifts
+		amount := detail.Subtotal * quantity / detail.Quantity
+		tax := detail.TaxAmount * quantity / detail.Quantity
+		if quantity == remaining {
+			amount = detail.Subtotal - detail.RefundedAmount
+			tax = detail.TaxAmount - detail.RefundedTaxAmount
+		}
+
+		refund.Amount += amount
+		refund.Items = append(refund.Items, models.RefundItem{
+			TransactionDetailID: detailID,
+			ProductID:           detail.ProductID,
+			Quantity:            quantity,
+			Amount:              amount,
+			TaxAmount:           tax,
+		})
+	}
+
+	return nil
 }
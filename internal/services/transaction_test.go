@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories/memory"
+	"sync"
+	"testing"
+	"time"
+)
+
+// the memory store starts with location 1, which the services default to
+const testLocationID = 1
+
+func newTestServices(store *memory.Store) (*ProductService, *TransactionService) {
+	locationRepo := memory.NewLocationRepo(store)
+	products := NewProductService(memory.NewProductRepo(store), memory.NewCategoryRepo(store), locationRepo,
+		memory.NewTaxClassRepo(store), testLocationID)
+	transactions := NewTransactionService(memory.NewTransactionRepo(store, time.UTC), locationRepo, memory.NewPromotionRepo(store),
+		testLocationID, models.TaxInclusive, 0, time.UTC)
+
+	return products, transactions
+}
+
+func createProduct(t *testing.T, products *ProductService, name string, price int, stock int) *models.Product {
+	t.Helper()
+
+	product := models.Product{Name: name, Price: price, Stock: stock}
+	if err := products.Create(context.Background(), &product, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	return &product
+}
+
+func assertStock(t *testing.T, products *ProductService, id int, want int) {
+	t.Helper()
+
+	product, err := products.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if product.Stock != want {
+		t.Errorf("%s has stock %d, want %d", product.Name, product.Stock, want)
+	}
+}
+
+func cash(amount int) []models.Payment {
+	return []models.Payment{{Method: models.PaymentMethodCash, Amount: amount}}
+}
+
+func TestCheckoutDecrementsStock(t *testing.T) {
+	ctx := context.Background()
+	products, transactions := newTestServices(memory.NewStore())
+	coffee := createProduct(t, products, "Coffee", 1500, 10)
+
+	transaction, err := transactions.Checkout(ctx, models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: coffee.ID, Quantity: 3}},
+		Payments: cash(5000),
+	}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if transaction.TotalAmount != 4500 || transaction.ChangeAmount != 500 {
+		t.Errorf("total %d change %d, want 4500 and 500", transaction.TotalAmount, transaction.ChangeAmount)
+	}
+	assertStock(t, products, coffee.ID, 7)
+}
+
+func TestCheckoutRollsBack(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int
+		paid     int
+		code     string
+	}{
+		{"out of stock", 3, 10000, "out_of_stock"},
+		{"insufficient payment", 1, 100, "insufficient_payment"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			products, transactions := newTestServices(memory.NewStore())
+			coffee := createProduct(t, products, "Coffee", 1500, 10)
+			tea := createProduct(t, products, "Tea", 1000, 2)
+
+			// coffee can be sold but the checkout as a whole fails, nothing may be taken
+			_, err := transactions.Checkout(ctx, models.CheckoutRequest{
+				Items: []models.CheckoutItem{
+					{ProductID: coffee.ID, Quantity: 1},
+					{ProductID: tea.ID, Quantity: test.quantity},
+				},
+				Payments: cash(test.paid),
+			}, "test")
+			if appErr := apperrors.As(err); appErr == nil || appErr.Code != test.code {
+				t.Fatalf("got %v, want %s", err, test.code)
+			}
+
+			assertStock(t, products, coffee.ID, 10)
+			assertStock(t, products, tea.ID, 2)
+		})
+	}
+}
+
+// TestCheckoutAmountTooLarge checks totals past what the columns hold are refused
+// rather than overflowing
+func TestCheckoutAmountTooLarge(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int
+		payments []models.Payment
+		code     string
+	}{
+		{"line", 2, cash(maxAmount), "amount_too_large"},
+		{"quantity", maxAmount + 1, cash(maxAmount), "validation_failed"},
+		{"payment", 1, cash(maxAmount + 1), "invalid_payment_amount"},
+		{"paid", 1, append(cash(maxAmount), cash(maxAmount)...), "amount_too_large"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			products, transactions := newTestServices(memory.NewStore())
+			gold := createProduct(t, products, "Gold bar", 2_000_000_000, 10)
+
+			_, err := transactions.Checkout(ctx, models.CheckoutRequest{
+				Items:    []models.CheckoutItem{{ProductID: gold.ID, Quantity: test.quantity}},
+				Payments: test.payments,
+			}, "test")
+			if appErr := apperrors.As(err); appErr == nil || appErr.Kind != apperrors.KindValidation || appErr.Code != test.code {
+				t.Fatalf("got %v, want a validation error %s", err, test.code)
+			}
+			assertStock(t, products, gold.ID, 10)
+		})
+	}
+
+	// lines that each fit but add up past the limit
+	ctx := context.Background()
+	products, transactions := newTestServices(memory.NewStore())
+	gold := createProduct(t, products, "Gold bar", 2_000_000_000, 10)
+	silver := createProduct(t, products, "Silver bar", 200_000_000, 10)
+	_, err := transactions.Checkout(ctx, models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: gold.ID, Quantity: 1}, {ProductID: silver.ID, Quantity: 1}},
+		Payments: cash(maxAmount),
+	}, "test")
+	if appErr := apperrors.As(err); appErr == nil || appErr.Code != "amount_too_large" {
+		t.Errorf("got %v, want amount_too_large", err)
+	}
+}
+
+// TestConcurrentCheckoutMemoryStore races more buyers than there are units, the store lock must
+// let exactly stock of them through
+func TestConcurrentCheckoutMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	const stock, buyers = 5, 20
+
+	products, transactions := newTestServices(memory.NewStore())
+	coffee := createProduct(t, products, "Coffee", 1500, stock)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, buyers)
+	for range buyers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := transactions.Checkout(ctx, models.CheckoutRequest{
+				Items:    []models.CheckoutItem{{ProductID: coffee.ID, Quantity: 1}},
+				Payments: cash(coffee.Price),
+			}, "test")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		if appErr := apperrors.As(err); !errors.Is(err, apperrors.ErrInsufficientStock) || appErr.Code != "out_of_stock" {
+			t.Errorf("checkout failed with %v, want out_of_stock", err)
+		}
+	}
+	if succeeded != stock {
+		t.Errorf("%d checkouts succeeded, want %d", succeeded, stock)
+	}
+	assertStock(t, products, coffee.ID, 0)
+}
+
+func TestRefundRestocks(t *testing.T) {
+	ctx := context.Background()
+	products, transactions := newTestServices(memory.NewStore())
+	coffee := createProduct(t, products, "Coffee", 1500, 10)
+
+	transaction, err := transactions.Checkout(ctx, models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: coffee.ID, Quantity: 4}},
+		Payments: cash(6000),
+	}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refund, err := transactions.Refund(ctx, transaction.ID, models.RefundRequest{
+		Items:   []models.RefundItem{{TransactionDetailID: transaction.Details[0].ID, Quantity: 3}},
+		Restock: true,
+	}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.Amount != 4500 {
+		t.Errorf("refunded %d, want 4500", refund.Amount)
+	}
+	assertStock(t, products, coffee.ID, 9)
+
+	// only one unit is left to refund
+	_, err = transactions.Refund(ctx, transaction.ID, models.RefundRequest{
+		Items:   []models.RefundItem{{TransactionDetailID: transaction.Details[0].ID, Quantity: 2}},
+		Restock: true,
+	}, "test")
+	if err == nil {
+		t.Error("refunding more than was sold succeeded")
+	}
+	assertStock(t, products, coffee.ID, 9)
+}
+
+func TestRefundWithoutRestock(t *testing.T) {
+	ctx := context.Background()
+	products, transactions := newTestServices(memory.NewStore())
+	coffee := createProduct(t, products, "Coffee", 1500, 10)
+
+	transaction, err := transactions.Checkout(ctx, models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: coffee.ID, Quantity: 2}},
+		Payments: cash(3000),
+	}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no items refunds everything, the goods stay out of stock
+	refund, err := transactions.Refund(ctx, transaction.ID, models.RefundRequest{}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.Amount != 3000 {
+		t.Errorf("refunded %d, want 3000", refund.Amount)
+	}
+	assertStock(t, products, coffee.ID, 8)
+}
+
+// TestReportDaysInLocation checks sales land on the date of the store's time zone. The
+// two zones are 26 hours apart, so the sale is always on different dates in them.
+func TestReportDaysInLocation(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	products, _ := newTestServices(store)
+	coffee := createProduct(t, products, "Coffee", 1500, 10)
+
+	newService := func(location *time.Location) *TransactionService {
+		return NewTransactionService(memory.NewTransactionRepo(store, location), memory.NewLocationRepo(store),
+			memory.NewPromotionRepo(store), testLocationID, models.TaxInclusive, 0, location)
+	}
+	east := newService(time.FixedZone("+14", 14*60*60))
+	west := newService(time.FixedZone("-12", -12*60*60))
+
+	if _, err := east.Checkout(ctx, models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: coffee.ID, Quantity: 1}},
+		Payments: cash(1500),
+	}, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		service *TransactionService
+		date    string
+		want    int
+	}{
+		{"east today", east, east.Today(), 1},
+		{"west today", west, west.Today(), 1},
+		{"east date in the west", west, east.Today(), 0},
+		{"west date in the east", east, west.Today(), 0},
+	}
+
+	for _, test := range tests {
+		report, err := test.service.Report(ctx, test.date, test.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.TotalTransaction != test.want {
+			t.Errorf("%s: %d transactions on %s, want %d", test.name, report.TotalTransaction, test.date, test.want)
+		}
+	}
+}
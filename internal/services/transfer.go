@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+)
+
+type TransferService struct {
+	repo         repositories.TransferRepository
+	locationRepo repositories.LocationRepository
+}
+
+func NewTransferService(repo repositories.TransferRepository, locationRepo repositories.LocationRepository) *TransferService {
+	return &TransferService{repo: repo, locationRepo: locationRepo}
+}
+
+func (s *TransferService) GetAll(ctx context.Context, status string) ([]models.StockTransfer, error) {
+	ctx, span := tracer.Start(ctx, "TransferService.GetAll")
+	defer span.End()
+
+	switch status {
+	case "", models.TransferStatusInTransit, models.TransferStatusReceived, models.TransferStatusCancelled:
+	default:
+		return nil, apperrors.Validation("invalid_status", "unknown transfer status %q", status)
+	}
+
+	return s.repo.GetAll(ctx, status)
+}
+
+func (s *TransferService) GetByID(ctx context.Context, id int) (*models.StockTransfer, error) {
+	ctx, span := tracer.Start(ctx, "TransferService.GetByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// Create takes the stock out of the source location right away, it is added to the
+// destination once the transfer is received
+func (s *TransferService) Create(ctx context.Context, transfer *models.StockTransfer, actor string) error {
+	ctx, span := tracer.Start(ctx, "TransferService.Create")
+	defer span.End()
+
+	if transfer.FromLocationID == transfer.ToLocationID {
+		return apperrors.Validation("same_location", "from_location_id and to_location_id must be different")
+	}
+	if _, err := s.locationRepo.GetByID(ctx, transfer.FromLocationID); err != nil {
+		return err
+	}
+	if _, err := s.locationRepo.GetByID(ctx, transfer.ToLocationID); err != nil {
+		return err
+	}
+
+	// merge repeated products into one line
+	merged := make([]models.StockTransferItem, 0, len(transfer.Items))
+	index := make(map[int]int)
+	for _, item := range transfer.Items {
+		if item.Quantity <= 0 {
+			return apperrors.Validation("invalid_quantity", "transfer quantity must be greater than zero")
+		}
+		if i, exists := index[item.ProductID]; exists {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.ProductID] = len(merged)
+		merged = append(merged, models.StockTransferItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	if len(merged) == 0 {
+		return apperrors.Validation("empty_transfer", "transfer has no items")
+	}
+
+	transfer.Items = merged
+	transfer.CreatedBy = actor
+
+	return s.repo.Create(ctx, transfer)
+}
+
+func (s *TransferService) Receive(ctx context.Context, id int, actor string) (*models.StockTransfer, error) {
+	ctx, span := tracer.Start(ctx, "TransferService.Receive")
+	defer span.End()
+
+	return s.repo.Complete(ctx, id, models.TransferStatusReceived, actor)
+}
+
+func (s *TransferService) Cancel(ctx context.Context, id int, actor string) (*models.StockTransfer, error) {
+	ctx, span := tracer.Start(ctx, "TransferService.Cancel")
+	defer span.End()
+
+	return s.repo.Complete(ctx, id, models.TransferStatusCancelled, actor)
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"store-api-go/internal/apperrors"
+	"store-api-go/internal/auth"
+	"store-api-go/internal/models"
+	"store-api-go/internal/repositories"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+// compared against on unknown usernames so login timing doesn't reveal which users exist
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+
+type UserService struct {
+	repo   repositories.UserRepository
+	tokens *auth.TokenManager
+}
+
+func NewUserService(repo repositories.UserRepository, tokens *auth.TokenManager) *UserService {
+	return &UserService{repo: repo, tokens: tokens}
+}
+
+func (s *UserService) GetAll(ctx context.Context) ([]models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetAll")
+	defer span.End()
+
+	return s.repo.GetAll(ctx)
+}
+
+func (s *UserService) Create(ctx context.Context, request models.CreateUserRequest) (*models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.Create")
+	defer span.End()
+
+	request.Username = strings.TrimSpace(request.Username)
+	if request.Username == "" {
+		return nil, apperrors.Validation("username_required", "username is required")
+	}
+	if len(request.Password) < minPasswordLength {
+		return nil, apperrors.Validation("password_too_short", "password must be at least 8 characters")
+	}
+	if !auth.IsValidRole(request.Role) {
+		return nil, apperrors.Validation("invalid_role", "role must be cashier, manager or admin")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		Username:     request.Username,
+		PasswordHash: string(hash),
+		Role:         request.Role,
+	}
+	if err := s.repo.Create(ctx, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (s *UserService) Login(ctx context.Context, request models.LoginRequest) (*models.LoginResponse, error) {
+	ctx, span := tracer.Start(ctx, "UserService.Login")
+	defer span.End()
+
+	invalid := apperrors.Unauthorized("invalid_credentials", "Invalid username or password")
+
+	user, err := s.repo.GetByUsername(ctx, strings.TrimSpace(request.Username))
+	if err != nil {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(request.Password))
+		return nil, invalid
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password)); err != nil {
+		return nil, invalid
+	}
+
+	token, expiresAt, err := s.tokens.Issue(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{Token: token, ExpiresAt: expiresAt, User: *user}, nil
+}
+
+// EnsureAdmin creates the first admin account when no users exist yet.
+// It returns false when users already exist and nothing was created.
+func (s *UserService) EnsureAdmin(ctx context.Context, username string, password string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "UserService.EnsureAdmin")
+	defer span.End()
+
+	count, err := s.repo.Count(ctx)
+	if err != nil || count > 0 {
+		return false, err
+	}
+
+	_, err = s.Create(ctx, models.CreateUserRequest{Username: username, Password: password, Role: models.RoleAdmin})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
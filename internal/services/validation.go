@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+	"store-api-go/internal/validate"
+)
+
+// validateUnique checks v's declared rules and that no row other than id already
+// uses name, which is reported as a failed "unique" rule on the name field
+func validateUnique(ctx context.Context, v any, name string, id int, nameExists func(ctx context.Context, name string, excludeID int) (bool, error)) error {
+	errs, err := validate.Struct(v)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		taken, err := nameExists(ctx, name, id)
+		if err != nil {
+			return err
+		}
+		if taken {
+			errs.Add("name", "unique", "name is already taken")
+		}
+	}
+
+	return errs.Err()
+}
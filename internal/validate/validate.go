@@ -0,0 +1,201 @@
+// Package validate checks structs against rules declared in `validate` struct tags:
+//
+//	required     not the zero value, strings must not be blank, slices not empty
+//	min=N max=N  bounds for numbers
+//	minlen=N maxlen=N  bounds for the length of strings (in characters) and slices
+//
+// Fields are reported by their JSON names. Nested structs and slices of structs are
+// checked too, so a bad checkout line is reported as "items[0].quantity". A malformed
+// tag is a bug, not bad input, and is returned as an error instead.
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"store-api-go/internal/apperrors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Struct returns every rule v breaks, v is a struct or a pointer to one. The error is
+// set when one of v's tags names an unknown rule or a bad limit.
+func Struct(v any) (apperrors.FieldErrors, error) {
+	errs := apperrors.FieldErrors{}
+	err := check(reflect.Indirect(reflect.ValueOf(v)), "", &errs)
+
+	return errs, err
+}
+
+func check(value reflect.Value, prefix string, errs *apperrors.FieldErrors) error {
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		fieldValue := value.Field(i)
+		if tag := field.Tag.Get("validate"); tag != "" {
+			if err := checkRules(fieldValue, path, tag, errs); err != nil {
+				return fmt.Errorf("validate: %s.%s: %w", value.Type(), field.Name, err)
+			}
+		}
+
+		switch fieldValue.Kind() {
+		case reflect.Struct:
+			if err := check(fieldValue, path, errs); err != nil {
+				return err
+			}
+		case reflect.Slice:
+			for j := 0; j < fieldValue.Len(); j++ {
+				if err := check(reflect.Indirect(fieldValue.Index(j)), fmt.Sprintf("%s[%d]", path, j), errs); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// rule is one parsed rule of a validate tag
+type rule struct {
+	name  string
+	limit int
+}
+
+// parseRules reads a validate tag, rejecting unknown rules and limits that aren't numbers
+func parseRules(tag string) ([]rule, error) {
+	rules := make([]rule, 0)
+	for _, part := range strings.Split(tag, ",") {
+		name, arg, hasArg := strings.Cut(strings.TrimSpace(part), "=")
+
+		switch name {
+		case "required":
+			if hasArg {
+				return nil, fmt.Errorf("rule %q takes no limit", name)
+			}
+			rules = append(rules, rule{name: name})
+		case "min", "max", "minlen", "maxlen":
+			limit, err := strconv.Atoi(arg)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q needs a number, got %q", name, arg)
+			}
+			rules = append(rules, rule{name: name, limit: limit})
+		default:
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+	}
+
+	return rules, nil
+}
+
+func checkRules(value reflect.Value, path string, tag string, errs *apperrors.FieldErrors) error {
+	rules, err := parseRules(tag)
+	if err != nil {
+		return err
+	}
+
+	// optional pointers are only checked when set
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			if strings.Contains(","+tag+",", ",required,") {
+				errs.Add(path, "required", "%s is required", path)
+			}
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	for _, rule := range rules {
+		limit := rule.limit
+
+		switch rule.name {
+		case "required":
+			if isBlank(value) {
+				errs.Add(path, "required", "%s is required", path)
+				// the other rules would only repeat the problem
+				return nil
+			}
+		case "min":
+			if number, ok := toInt(value); ok && number < int64(limit) {
+				errs.Add(path, "min", "%s must be at least %d", path, limit)
+			}
+		case "max":
+			if number, ok := toInt(value); ok && number > int64(limit) {
+				errs.Add(path, "max", "%s must be at most %d", path, limit)
+			}
+		case "minlen":
+			if length, ok := lengthOf(value); ok && length < limit {
+				errs.Add(path, "minlen", "%s must have at least %d %s", path, limit, unit(value))
+			}
+		case "maxlen":
+			if length, ok := lengthOf(value); ok && length > limit {
+				errs.Add(path, "maxlen", "%s must have at most %d %s", path, limit, unit(value))
+			}
+		}
+	}
+
+	return nil
+}
+
+func isBlank(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+func toInt(value reflect.Value) (int64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), true
+	default:
+		return 0, false
+	}
+}
+
+func lengthOf(value reflect.Value) (int, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(value.String()), true
+	case reflect.Slice, reflect.Map:
+		return value.Len(), true
+	default:
+		return 0, false
+	}
+}
+
+func unit(value reflect.Value) string {
+	if value.Kind() == reflect.String {
+		return "characters"
+	}
+
+	return "items"
+}
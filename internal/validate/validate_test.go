@@ -0,0 +1,70 @@
+package validate
+
+import (
+	"store-api-go/internal/models"
+	"testing"
+)
+
+// every model validated by the services, nested slices need an element to be walked
+var validated = []any{
+	models.Product{},
+	models.Category{},
+	models.CheckoutRequest{Items: []models.CheckoutItem{{}}},
+}
+
+func TestModelTags(t *testing.T) {
+	for _, v := range validated {
+		if _, err := Struct(v); err != nil {
+			t.Errorf("%T: %v", v, err)
+		}
+	}
+}
+
+func TestMalformedTags(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"unknown rule", struct {
+			Name string `validate:"requird"`
+		}{}},
+		{"limit not a number", struct {
+			Price int `validate:"min=zero"`
+		}{}},
+		{"required with limit", struct {
+			Name string `validate:"required=1"`
+		}{}},
+	}
+
+	for _, test := range tests {
+		if _, err := Struct(test.v); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestStruct(t *testing.T) {
+	request := models.CheckoutRequest{
+		LocationID: -1,
+		Items:      []models.CheckoutItem{{ProductID: 1, Quantity: 0}},
+	}
+
+	errs, err := Struct(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := make(map[string]string)
+	for _, fieldErr := range errs {
+		fields[fieldErr.Field] = fieldErr.Code
+	}
+	want := map[string]string{"location_id": "min", "items[0].quantity": "min"}
+	for field, code := range want {
+		if fields[field] != code {
+			t.Errorf("%s: got %q, want %q", field, fields[field], code)
+		}
+	}
+	if len(fields) != len(want) {
+		t.Errorf("got %v, want %v", fields, want)
+	}
+}
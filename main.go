@@ -1,98 +1,329 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"store-api-go/internal/auth"
+	"store-api-go/internal/config"
 	"store-api-go/internal/database"
 	"store-api-go/internal/handlers"
-	"store-api-go/internal/models"
+	"store-api-go/internal/idempotency"
+	"store-api-go/internal/logging"
+	"store-api-go/internal/metrics"
+	"store-api-go/internal/receipt"
 	"store-api-go/internal/repositories"
+	"store-api-go/internal/repositories/memory"
+	"store-api-go/internal/server"
 	"store-api-go/internal/services"
-	"strings"
-
-	"github.com/spf13/viper"
+	"store-api-go/internal/tracing"
+	"strconv"
+	"syscall"
+	"time"
 )
 
-// config
-type Config struct {
-	Port          string `mapstructure:"PORT"`
-	DBConn        string `mapstructure:"DB_CONN"`
-	DBMaxOpenConn string `mapstructure:"DB_MAX_OPEN_CONNECTION"`
-	BaseURL       string `mapstructure:"BASE_URL"`
-}
-
-// data
-var categories = []models.Category{
-	{ID: 1, Name: "Animal", Description: " A living thing that moves around to find food and eats plants or other animals for energy."},
-	{ID: 2, Name: "Plant", Description: "A living thing that has leaves and roots that usually grow in the ground."},
-	{ID: 3, Name: "Bacteria", Description: "Tiny, single-celled organisms with no nucleus."},
-}
-
-// main func
 func main() {
-	// Load the env
-	viper.AutomaticEnv()
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	if err := run(); err != nil {
+		slog.Error("Exiting", "error", err)
+		os.Exit(1)
+	}
+}
 
-	if _, err := os.Stat(".env"); err == nil {
-		viper.SetConfigFile(".env")
-		_ = viper.ReadInConfig()
+// run serves until a signal stops it. Errors are returned rather than exiting so the
+// deferred database close and trace flush still happen.
+func run() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
 	}
 
-	config := Config{
-		Port:          viper.GetString("PORT"),
-		DBConn:        viper.GetString("DB_CONN"),
-		DBMaxOpenConn: viper.GetString("DB_MAX_OPEN_CONNECTION"),
+	// set up first so the config errors below are logged in the configured format
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		return fmt.Errorf("logging config: %w", err)
 	}
+	// the log package, used by the http server and libraries, writes through it too
+	slog.SetDefault(logger)
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	// report days, date filters and receipts go by the store's time zone
+	location := cfg.Location()
+
+	// SIGTERM or ctrl-c stops the server, letting in flight requests finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// DB setup
-	db, err := database.InitDB(config.DBConn)
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Exporter:     cfg.TracingExporter,
+		ServiceName:  cfg.TracingServiceName,
+		SampleRatio:  cfg.TracingSampleRatio,
+		OTLPEndpoint: cfg.TracingOTLPEndpoint,
+		File:         cfg.TracingFile,
+	})
 	if err != nil {
-		log.Fatal("Failed to initialize database:", err)
+		return fmt.Errorf("set up tracing: %w", err)
 	}
-	defer db.Close()
+	// runs after the server stopped, flushing the spans of the last requests
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
 
 	// Define the layers
-	categoryRepo := repositories.NewCategoryRepo(db)
-	categoryService := services.NewCategoryService(categoryRepo)
+	var (
+		userRepo        repositories.UserRepository
+		categoryRepo    repositories.CategoryRepository
+		productRepo     repositories.ProductRepository
+		transactionRepo repositories.TransactionRepository
+		locationRepo    repositories.LocationRepository
+		transferRepo    repositories.TransferRepository
+		supplierRepo    repositories.SupplierRepository
+		orderRepo       repositories.PurchaseOrderRepository
+		promotionRepo   repositories.PromotionRepository
+		taxClassRepo    repositories.TaxClassRepository
+		idempotencyRepo repositories.IdempotencyRepository
+		healthHandler   *handlers.HealthHandler
+	)
+
+	switch cfg.StorageDriver {
+	case config.StorageMemory:
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			return errors.New("migrate requires STORAGE_DRIVER=postgres")
+		}
+
+		store := memory.NewStore()
+		userRepo = memory.NewUserRepo(store)
+		categoryRepo = memory.NewCategoryRepo(store)
+		productRepo = memory.NewProductRepo(store)
+		transactionRepo = memory.NewTransactionRepo(store, location)
+		locationRepo = memory.NewLocationRepo(store)
+		transferRepo = memory.NewTransferRepo(store)
+		supplierRepo = memory.NewSupplierRepo(store)
+		orderRepo = memory.NewPurchaseOrderRepo(store)
+		promotionRepo = memory.NewPromotionRepo(store)
+		taxClassRepo = memory.NewTaxClassRepo(store)
+		idempotencyRepo = memory.NewIdempotencyRepo(store)
+		healthHandler = handlers.NewHealthHandler(nil, nil, cfg.HealthCheckTimeout)
+		slog.Warn("Using in-memory storage, data is lost on restart")
+	default:
+		// DB setup
+		db, err := database.InitDB(cfg.DBConn, cfg.DBMaxOpenConns, cfg.DBMaxIdleConns, cfg.TimeZone)
+		if err != nil {
+			return fmt.Errorf("initialize database: %w", err)
+		}
+		defer db.Close()
+		metrics.RegisterDB(db, "postgres")
+
+		// `store-api-go migrate up|down [steps]|status` manages the schema and exits
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			if err := runMigrate(db, os.Args[2:]); err != nil {
+				return fmt.Errorf("migrate: %w", err)
+			}
+			return nil
+		}
+
+		migrator, err := database.NewMigrator(db)
+		if err != nil {
+			return fmt.Errorf("load migrations: %w", err)
+		}
+
+		if cfg.DBAutoMigrate {
+			applied, err := migrator.Up()
+			if err != nil {
+				return fmt.Errorf("migrate: %w", err)
+			}
+			slog.Info("Applied migrations", "count", applied)
+		}
+
+		userRepo = repositories.NewUserRepo(db)
+		categoryRepo = repositories.NewCategoryRepo(db)
+		productRepo = repositories.NewProductRepo(db)
+		transactionRepo = repositories.NewTransactionRepo(db, location)
+		locationRepo = repositories.NewLocationRepo(db)
+		transferRepo = repositories.NewTransferRepo(db)
+		supplierRepo = repositories.NewSupplierRepo(db)
+		orderRepo = repositories.NewPurchaseOrderRepo(db)
+		promotionRepo = repositories.NewPromotionRepo(db)
+		taxClassRepo = repositories.NewTaxClassRepo(db)
+		idempotencyRepo = repositories.NewIdempotencyRepo(db)
+		healthHandler = handlers.NewHealthHandler(db, migrator, cfg.HealthCheckTimeout)
+	}
+
+	tokens := auth.NewTokenManager(cfg.AuthSecret, cfg.AuthTokenTTL)
+
+	// a request can't run past the write timeout, a key held longer than a few of them
+	// belongs to a request that died
+	idempotent := idempotency.New(idempotencyRepo, cfg.IdempotencyTTL, 3*cfg.HTTPWriteTimeout)
+	go idempotent.Purge(ctx, time.Hour)
+	userService := services.NewUserService(userRepo, tokens)
+	userHandler := handlers.NewUserHandler(userService)
+
+	if cfg.AuthAdminUsername != "" {
+		created, err := userService.EnsureAdmin(ctx, cfg.AuthAdminUsername, cfg.AuthAdminPassword)
+		if err != nil {
+			return fmt.Errorf("create admin user: %w", err)
+		}
+		if created {
+			slog.Info("Created admin user", "username", cfg.AuthAdminUsername)
+		}
+	}
+
+	categoryService := services.NewCategoryService(categoryRepo, productRepo, cfg.CategoryDeletePolicy)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 
-	productRepo := repositories.NewProductRepo(db)
-	productService := services.NewProductService(productRepo)
+	productService := services.NewProductService(productRepo, categoryRepo, locationRepo, taxClassRepo, cfg.DefaultLocationID)
 	productHandler := handlers.NewProductHandler(productService)
 
-	transactionRepo := repositories.NewTransactionRepo(db)
-	transactionService := services.NewTransactionService(transactionRepo)
-	transactionHandler := handlers.NewTransactionHandler(transactionService)
-
-	// Setup routes
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{
-			"status":  "OK",
-			"message": "Server is running",
-		})
-	})
+	transactionService := services.NewTransactionService(transactionRepo, locationRepo, promotionRepo, cfg.DefaultLocationID,
+		cfg.TaxPricingMode, cfg.TaxDefaultRate, location)
+	receipts, err := receipt.New(receipt.Store{
+		Name:    cfg.ReceiptStoreName,
+		Address: cfg.ReceiptStoreAddress,
+		Footer:  cfg.ReceiptFooter,
+	}, cfg.ReceiptTemplateDir, location)
+	if err != nil {
+		return fmt.Errorf("load receipt templates: %w", err)
+	}
+	transactionHandler := handlers.NewTransactionHandler(transactionService, receipts)
+
+	locationService := services.NewLocationService(locationRepo)
+	locationHandler := handlers.NewLocationHandler(locationService)
 
-	http.HandleFunc("/api/categories", categoryHandler.HandleCategories)
-	http.HandleFunc("/api/categories/", categoryHandler.HandleCategoryByID)
+	transferService := services.NewTransferService(transferRepo, locationRepo)
+	transferHandler := handlers.NewTransferHandler(transferService)
 
-	http.HandleFunc("/api/products", productHandler.HandleProducts)
-	http.HandleFunc("/api/products/", productHandler.HandleProductByID)
+	supplierService := services.NewSupplierService(supplierRepo)
+	supplierHandler := handlers.NewSupplierHandler(supplierService)
 
-	http.HandleFunc("/api/checkout", transactionHandler.HandleCheckout)
+	orderService := services.NewPurchaseOrderService(orderRepo, supplierRepo, locationRepo, cfg.DefaultLocationID)
+	orderHandler := handlers.NewPurchaseOrderHandler(orderService)
 
-	http.HandleFunc("/api/report/hari-ini", transactionHandler.HandleReportToday)
+	promotionService := services.NewPromotionService(promotionRepo, productRepo, categoryRepo)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+
+	taxClassService := services.NewTaxClassService(taxClassRepo)
+	taxClassHandler := handlers.NewTaxClassHandler(taxClassService)
+
+	handler := server.NewHandler(server.Handlers{
+		Tokens:      tokens,
+		Idempotency: idempotent,
+		Health:      healthHandler,
+
+		Users:          userHandler,
+		Categories:     categoryHandler,
+		Products:       productHandler,
+		Transactions:   transactionHandler,
+		Locations:      locationHandler,
+		Transfers:      transferHandler,
+		Suppliers:      supplierHandler,
+		PurchaseOrders: orderHandler,
+		Promotions:     promotionHandler,
+		TaxClasses:     taxClassHandler,
+	}, cfg.CORSAllowedOrigins)
 
 	// Serve the api
-	address := config.BaseURL + ":" + config.Port
-	fmt.Println("Server running on ", address)
+	slog.Info("Server running", "address", cfg.Addr())
+
+	srv := server.New(server.Config{
+		Addr:              cfg.Addr(),
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+		ShutdownTimeout:   cfg.ShutdownTimeout,
+		ShutdownDelay:     cfg.ShutdownDelay,
+	}, handler)
+	// probes fail first so the load balancer stops sending traffic before we stop serving
+	srv.OnShutdown(healthHandler.Drain)
+
+	// metrics carry revenue, they get their own listener instead of a public route
+	metricsDone := make(chan struct{})
+	go func() {
+		defer close(metricsDone)
+		if cfg.MetricsAddr == "" {
+			return
+		}
 
-	err = http.ListenAndServe(":"+config.Port, nil)
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("GET /metrics", metrics.Handler())
+		slog.Info("Metrics server running", "address", cfg.MetricsAddr)
+		metricsSrv := server.New(server.Config{
+			Addr:              cfg.MetricsAddr,
+			ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+			WriteTimeout:      cfg.HTTPWriteTimeout,
+			ShutdownTimeout:   cfg.ShutdownTimeout,
+		}, metricsMux)
+		if err := metricsSrv.Run(ctx); err != nil {
+			slog.Error("Metrics server stopped", "error", err)
+		}
+	}()
+
+	err = srv.Run(ctx)
+	stop()
+	<-metricsDone
+	if err != nil {
+		return fmt.Errorf("server stopped: %w", err)
+	}
+	slog.Info("Server stopped")
+
+	return nil
+}
+
+func runMigrate(db *sql.DB, args []string) error {
+	migrator, err := database.NewMigrator(db)
 	if err != nil {
-		fmt.Print(err)
+		return err
 	}
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", command)
+	}
+
+	return nil
 }